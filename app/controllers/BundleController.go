@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// attachBundleItems fills BundleItems of every bundle in the listing.
+func attachBundleItems(products []model.ProductResponse) error {
+	var ids []string
+	for _, p := range products {
+		if p.Type == "bundle" {
+			ids = append(ids, p.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := config.DB.Query(`
+    SELECT bi.bundleId, bi.productId, p.name, p.sku, bi.quantity
+    FROM bundle_items bi
+    JOIN products p ON p.id = bi.productId
+    WHERE bi.bundleId = ANY($1::int[])
+    ORDER BY bi.id ASC`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	items := map[string][]model.BundleItemResponse{}
+	for rows.Next() {
+		var bundleID string
+		var item model.BundleItemResponse
+		if err := rows.Scan(&bundleID, &item.ProductID, &item.Name, &item.SKU, &item.Quantity); err != nil {
+			return err
+		}
+		items[bundleID] = append(items[bundleID], item)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range products {
+		products[i].BundleItems = items[products[i].ID]
+	}
+	return nil
+}
+
+// validateBundleItems checks that every component is listed once and is an
+// existing single product; bundles cannot contain other bundles.
+func validateBundleItems(items []model.BundleItemRequest) (bool, error) {
+	seen := map[string]bool{}
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if seen[item.ProductID] {
+			return false, nil
+		}
+		seen[item.ProductID] = true
+		ids = append(ids, item.ProductID)
+	}
+
+	var count int
+	err := config.DB.QueryRow("SELECT COUNT(*) FROM products WHERE id = ANY($1::int[]) AND type = 'single' AND deletedAt IS NULL", pq.Array(ids)).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count == len(ids), nil
+}
+
+// validateBundleRequest runs the checks shared by AddBundle and UpdateBundle
+// and writes the error response itself; it returns false when the request
+// must stop.
+func validateBundleRequest(c *gin.Context, bundle model.BundleRequest) bool {
+	if !helper.ValidateURL(bundle.ImageURL) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return false
+	}
+
+	isActive, err := categoryIsActive(bundle.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category"})
+		return false
+	}
+	if !isActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+		return false
+	}
+
+	isValid, err := validateBundleItems(bundle.Items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bundle items"})
+		return false
+	}
+	if !isValid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bundle items must be distinct existing products"})
+		return false
+	}
+	return true
+}
+
+func saveBundleItems(tx *sql.Tx, bundleID string, items []model.BundleItemRequest) error {
+	if _, err := tx.Exec("DELETE FROM bundle_items WHERE bundleId = $1", bundleID); err != nil {
+		return err
+	}
+	for _, item := range items {
+		_, err := tx.Exec("INSERT INTO bundle_items (bundleId, productId, quantity) VALUES ($1, $2, $3)", bundleID, item.ProductID, item.Quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func AddBundle(c *gin.Context) {
+	var bundle model.BundleRequest
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if !validateBundleRequest(c, bundle) {
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Bundle"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Bundle stock is always derived from its components, so the stored
+	// stock stays 0.
+	var lastInsertedID int
+	err = tx.QueryRow(`
+    INSERT INTO products (name, sku, categoryId, imageUrl, notes, price, stock, location, isAvailable, type, bundlePricing, bundleDiscount)
+    VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8, 'bundle', $9, $10)
+    RETURNING id`,
+		bundle.Name, bundle.SKU, bundle.CategoryID, bundle.ImageURL, bundle.Notes, bundle.Price, bundle.Location, *bundle.IsAvailable, bundle.Pricing, bundle.Discount).Scan(&lastInsertedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Bundle"})
+		return
+	}
+	if err := saveBundleItems(tx, strconv.Itoa(lastInsertedID), bundle.Items); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Bundle"})
+		return
+	}
+	if err := auditProductCreate(tx, strconv.Itoa(lastInsertedID), c.GetInt("userId")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Bundle"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Bundle"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Bundle added successfully",
+		"data":    gin.H{"id": strconv.Itoa(lastInsertedID)},
+	})
+}
+
+func UpdateBundle(c *gin.Context) {
+	var bundle model.BundleRequest
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	bundleID := c.Param("id")
+
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND type = 'bundle' AND deletedAt IS NULL)", bundleID).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check bundle existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bundle not found"})
+		return
+	}
+	if !validateBundleRequest(c, bundle) {
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Bundle"})
+		return
+	}
+	defer tx.Rollback()
+
+	if err := lockProductVersion(c, tx, bundleID); err != nil {
+		respondRequestError(c, err, "Error when Update Bundle")
+		return
+	}
+	query := `
+    UPDATE products
+    SET
+        name = $1,
+        sku = $2,
+        categoryId = $3,
+        imageUrl = $4,
+        notes = $5,
+        price = $6,
+        location = $7,
+        isAvailable = $8,
+        bundlePricing = $9,
+        bundleDiscount = $10,
+        version = version + 1,
+        updatedAt = NOW()
+    WHERE
+        id = $11
+        AND deletedAt IS NULL
+`
+	err = withProductAudit(tx, bundleID, "update", c.GetInt("userId"), func() error {
+		_, err := tx.Exec(query, bundle.Name, bundle.SKU, bundle.CategoryID, bundle.ImageURL, bundle.Notes, bundle.Price, bundle.Location, *bundle.IsAvailable, bundle.Pricing, bundle.Discount, bundleID)
+		if err != nil {
+			return err
+		}
+		return saveBundleItems(tx, bundleID, bundle.Items)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Bundle"})
+		return
+	}
+	etag, err := productETag(tx, bundleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Bundle"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Bundle"})
+		return
+	}
+	c.Header("ETag", etag)
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Bundle"})
+}
@@ -0,0 +1,391 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+// reservedStock is the quantity of the stock row whose stock_reservations
+// column equals id that is held by carts which have not expired yet.
+func reservedStock(column, id string) string {
+	return `COALESCE((
+                SELECT SUM(sr.quantity) FROM stock_reservations sr
+                WHERE sr.` + column + ` = ` + id + ` AND sr.expiresAt > NOW()
+            ), 0)`
+}
+
+// reservationColumn is the stock_reservations column pointing at rows of a
+// stockKey table.
+func reservationColumn(table string) string {
+	if table == "product_variants" {
+		return "variantId"
+	}
+	return "productId"
+}
+
+// lockOpenCart locks a cart that can still be changed or checked out and
+// returns its customer.
+func lockOpenCart(tx *sql.Tx, cartID string) (string, error) {
+	var customerID, status string
+	var live bool
+	err := tx.QueryRow("SELECT COALESCE(customerId::text, ''), status, expiresAt > NOW() FROM carts WHERE id = $1 FOR UPDATE", cartID).Scan(&customerID, &status, &live)
+	if err == sql.ErrNoRows {
+		return "", &requestError{http.StatusNotFound, "Cart not found"}
+	}
+	if err != nil {
+		return "", err
+	}
+	if status != "open" {
+		return "", &requestError{http.StatusConflict, "Cart is not open"}
+	}
+	if !live {
+		return "", &requestError{http.StatusConflict, "Cart has expired"}
+	}
+	return customerID, nil
+}
+
+func cartDetails(tx *sql.Tx, cartID string) ([]model.CheckoutProductDetail, error) {
+	rows, err := tx.Query("SELECT productId, COALESCE(variantId::text, ''), quantity FROM cart_items WHERE cartId = $1 ORDER BY id ASC", cartID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var details []model.CheckoutProductDetail
+	for rows.Next() {
+		var d model.CheckoutProductDetail
+		if err := rows.Scan(&d.ProductID, &d.VariantID, &d.Quantity); err != nil {
+			return nil, err
+		}
+		details = append(details, d)
+	}
+	return details, rows.Err()
+}
+
+// addCartItem adds a line to a cart, or more items to the line when the
+// product or variant is already in it.
+func addCartItem(tx *sql.Tx, cartID string, item model.CheckoutProductDetail) error {
+	if _, err := buildCheckoutLines(tx, []model.CheckoutProductDetail{item}); err != nil {
+		return err
+	}
+	var variantID interface{}
+	if item.VariantID != "" {
+		variantID = item.VariantID
+	}
+	_, err := tx.Exec(`
+    INSERT INTO cart_items (cartId, productId, variantId, quantity) VALUES ($1, $2, $3, $4)
+    ON CONFLICT (cartId, productId, COALESCE(variantId, 0))
+    DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity, updatedAt = NOW()`,
+		cartID, item.ProductID, variantID, item.Quantity)
+	return err
+}
+
+// reserveCart replaces the reservations of a cart with what its lines need
+// now and extends the cart for another CART_RESERVATION_MINUTES. Stock rows
+// are locked in a fixed order, so two carts reserving the same rows cannot
+// both take the last items.
+func reserveCart(tx *sql.Tx, cartID string) error {
+	if _, err := tx.Exec("DELETE FROM stock_reservations WHERE cartId = $1", cartID); err != nil {
+		return err
+	}
+	ttlMinutes := config.GetEnvInt("CART_RESERVATION_MINUTES", 15)
+	var expiresAt time.Time
+	err := tx.QueryRow("UPDATE carts SET expiresAt = NOW() + make_interval(mins => $2), updatedAt = NOW() WHERE id = $1 RETURNING expiresAt", cartID, ttlMinutes).Scan(&expiresAt)
+	if err != nil {
+		return err
+	}
+
+	details, err := cartDetails(tx, cartID)
+	if err != nil || len(details) == 0 {
+		return err
+	}
+	lines, err := buildCheckoutLines(tx, details)
+	if err != nil {
+		return err
+	}
+	quantities, keys, err := stockQuantities(tx, lines)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		column := reservationColumn(key.table)
+		var stock int
+		err := tx.QueryRow("SELECT stock FROM "+key.table+" WHERE id = $1 AND isAvailable = true AND deletedAt IS NULL FOR UPDATE", key.id).Scan(&stock)
+		if err == sql.ErrNoRows {
+			return &requestError{http.StatusBadRequest, "Insufficient stock"}
+		}
+		if err != nil {
+			return err
+		}
+		var reserved int
+		if err := tx.QueryRow("SELECT "+reservedStock(column, "$1"), key.id).Scan(&reserved); err != nil {
+			return err
+		}
+		if stock-reserved < quantities[key] {
+			return &requestError{http.StatusBadRequest, "Insufficient stock"}
+		}
+		_, err = tx.Exec("INSERT INTO stock_reservations (cartId, "+column+", quantity, expiresAt) VALUES ($1, $2, $3, $4)",
+			cartID, key.id, quantities[key], expiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkoutCart takes the lines of a cart for checkout and releases its
+// reservations, so the sale can take the stock they held.
+func checkoutCart(tx *sql.Tx, cartID string, customerID string) ([]model.CheckoutProductDetail, error) {
+	cartCustomerID, err := lockOpenCart(tx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if cartCustomerID != "" && cartCustomerID != customerID {
+		return nil, &requestError{http.StatusBadRequest, "Customer does not match cart"}
+	}
+	details, err := cartDetails(tx, cartID)
+	if err != nil {
+		return nil, err
+	}
+	if len(details) == 0 {
+		return nil, &requestError{http.StatusBadRequest, "Cart is empty"}
+	}
+	if _, err := tx.Exec("DELETE FROM stock_reservations WHERE cartId = $1", cartID); err != nil {
+		return nil, err
+	}
+	return details, nil
+}
+
+func loadCart(db dbExecutor, cartID string) (model.CartResponse, error) {
+	var cart model.CartResponse
+	err := db.QueryRow(`
+    SELECT id, staffId, COALESCE(customerId::text, ''),
+        CASE WHEN status = 'open' AND expiresAt <= NOW() THEN 'expired' ELSE status END,
+        COALESCE(transactionId::text, ''), expiresAt, createdAt, updatedAt
+    FROM carts WHERE id = $1`, cartID).Scan(&cart.ID, &cart.StaffID, &cart.CustomerID, &cart.Status, &cart.TransactionID, &cart.ExpiresAt, &cart.CreatedAt, &cart.UpdatedAt)
+	if err != nil {
+		return cart, err
+	}
+
+	rows, err := db.Query("SELECT id, productId, COALESCE(variantId::text, ''), quantity FROM cart_items WHERE cartId = $1 ORDER BY id ASC", cartID)
+	if err != nil {
+		return cart, err
+	}
+	defer rows.Close()
+	cart.Items = []model.CartItemResponse{}
+	for rows.Next() {
+		var item model.CartItemResponse
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.VariantID, &item.Quantity); err != nil {
+			return cart, err
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	return cart, rows.Err()
+}
+
+// changeCart runs change on an open cart, re-reserves its stock and
+// responds with the updated cart.
+func changeCart(c *gin.Context, change func(tx *sql.Tx, cartID string) error, fallback string) {
+	cartID := c.Param("id")
+	if _, err := strconv.Atoi(cartID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := lockOpenCart(tx, cartID); err != nil {
+		respondRequestError(c, err, fallback)
+		return
+	}
+	if err := change(tx, cartID); err != nil {
+		respondRequestError(c, err, fallback)
+		return
+	}
+	if err := reserveCart(tx, cartID); err != nil {
+		respondRequestError(c, err, fallback)
+		return
+	}
+	cart, err := loadCart(tx, cartID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    cart,
+	})
+}
+
+func CreateCart(c *gin.Context) {
+	var request model.CartRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	var customerID interface{}
+	if request.CustomerID != "" {
+		exists, err := customerExists(request.CustomerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check customer"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+			return
+		}
+		customerID = request.CustomerID
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Create Cart"})
+		return
+	}
+	defer tx.Rollback()
+
+	var cartID string
+	err = tx.QueryRow("INSERT INTO carts (staffId, customerId, expiresAt) VALUES ($1, $2, NOW()) RETURNING id", c.GetInt("userId"), customerID).Scan(&cartID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Create Cart"})
+		return
+	}
+	for _, item := range request.ProductDetails {
+		if err := addCartItem(tx, cartID, item); err != nil {
+			respondRequestError(c, err, "Error when Create Cart")
+			return
+		}
+	}
+	if err := reserveCart(tx, cartID); err != nil {
+		respondRequestError(c, err, "Error when Create Cart")
+		return
+	}
+	cart, err := loadCart(tx, cartID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Create Cart"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Create Cart"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Cart created successfully",
+		"data":    cart,
+	})
+}
+
+func GetCart(c *gin.Context) {
+	cartID := c.Param("id")
+	if _, err := strconv.Atoi(cartID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+
+	cart, err := loadCart(config.DB, cartID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Cart"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    cart,
+	})
+}
+
+func AddCartItem(c *gin.Context) {
+	var request model.CartItemRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	changeCart(c, func(tx *sql.Tx, cartID string) error {
+		return addCartItem(tx, cartID, model.CheckoutProductDetail{ProductID: request.ProductID, VariantID: request.VariantID, Quantity: request.Quantity})
+	}, "Error when Add Cart Item")
+}
+
+func UpdateCartItem(c *gin.Context) {
+	var request model.CartItemUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	changeCart(c, func(tx *sql.Tx, cartID string) error {
+		result, err := tx.Exec("UPDATE cart_items SET quantity = $1, updatedAt = NOW() WHERE id::text = $2 AND cartId = $3",
+			request.Quantity, c.Param("itemId"), cartID)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return &requestError{http.StatusNotFound, "Cart item not found"}
+		}
+		return nil
+	}, "Error when Update Cart Item")
+}
+
+func DeleteCartItem(c *gin.Context) {
+	changeCart(c, func(tx *sql.Tx, cartID string) error {
+		result, err := tx.Exec("DELETE FROM cart_items WHERE id::text = $1 AND cartId = $2", c.Param("itemId"), cartID)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return &requestError{http.StatusNotFound, "Cart item not found"}
+		}
+		return nil
+	}, "Error when Delete Cart Item")
+}
+
+// CancelCart abandons a cart and releases the stock it holds.
+func CancelCart(c *gin.Context) {
+	cartID := c.Param("id")
+	if _, err := strconv.Atoi(cartID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Cancel Cart"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE carts SET status = 'cancelled', updatedAt = NOW() WHERE id = $1 AND status = 'open'", cartID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Cancel Cart"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+	if _, err := tx.Exec("DELETE FROM stock_reservations WHERE cartId = $1", cartID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Cancel Cart"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Cancel Cart"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Cancel Cart"})
+}
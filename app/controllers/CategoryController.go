@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+// categoryTreeFilter restricts products to the categories matched by cond
+// and all of their descendants.
+func categoryTreeFilter(cond string) string {
+	return " AND p.categoryId IN (" +
+		"WITH RECURSIVE tree AS (" +
+		"SELECT id FROM categories WHERE " + cond + " AND deletedAt IS NULL" +
+		" UNION ALL " +
+		"SELECT ch.id FROM categories ch JOIN tree t ON ch.parentId = t.id WHERE ch.deletedAt IS NULL" +
+		") SELECT id FROM tree)"
+}
+
+func categoryIsActive(categoryID string) (bool, error) {
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND isActive = true AND deletedAt IS NULL)", categoryID).Scan(&exists)
+	return exists, err
+}
+
+func AddCategory(c *gin.Context) {
+	var category model.CategoryRequest
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	if category.ParentID != nil {
+		var exists bool
+		err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND deletedAt IS NULL)", *category.ParentID).Scan(&exists)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check parent category"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent category not found"})
+			return
+		}
+	}
+
+	var lastInsertedID int
+	err := config.DB.QueryRow("INSERT INTO categories (name, parentId, displayOrder, isActive) VALUES ($1, $2, $3, $4) RETURNING id",
+		category.Name, category.ParentID, category.DisplayOrder, *category.IsActive).Scan(&lastInsertedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Category"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Category added successfully",
+		"data":    gin.H{"id": strconv.Itoa(lastInsertedID)},
+	})
+}
+
+func GetAllCategory(c *gin.Context) {
+	categories := []model.CategoryResponse{}
+	query := "SELECT id, name, parentId, taxClassId::text, displayOrder, isActive, createdAt FROM categories WHERE 1=1 AND deletedAt IS NULL"
+	args := []interface{}{}
+
+	var params model.GetCategoryParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	if params.ID != "" {
+		query += " AND id = $" + strconv.Itoa(len(args)+1)
+		args = append(args, params.ID)
+	}
+	if params.Name != "" {
+		query += " AND lower(name) LIKE $" + strconv.Itoa(len(args)+1)
+		args = append(args, "%"+strings.ToLower(params.Name)+"%")
+	}
+	if params.ParentID != "" {
+		query += " AND parentId = $" + strconv.Itoa(len(args)+1)
+		args = append(args, params.ParentID)
+	}
+	if params.IsActive == "true" || params.IsActive == "1" {
+		query += " AND isActive = true"
+	} else if params.IsActive == "false" || params.IsActive == "0" {
+		query += " AND isActive = false"
+	}
+	query += " ORDER BY displayOrder ASC, name ASC"
+
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Category"})
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var cat model.CategoryResponse
+		if err := rows.Scan(&cat.ID, &cat.Name, &cat.ParentID, &cat.TaxClassID, &cat.DisplayOrder, &cat.IsActive, &cat.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Category"})
+			return
+		}
+		categories = append(categories, cat)
+	}
+
+	if params.Tree == "true" || params.Tree == "1" {
+		categories = buildCategoryTree(categories)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    categories,
+	})
+}
+
+// buildCategoryTree nests categories under their parents. Categories whose
+// parent is not part of the list are returned as roots, so filtered lists
+// still render every row.
+func buildCategoryTree(categories []model.CategoryResponse) []model.CategoryResponse {
+	present := map[string]bool{}
+	children := map[string][]model.CategoryResponse{}
+	for _, cat := range categories {
+		present[cat.ID] = true
+	}
+	var roots []model.CategoryResponse
+	for _, cat := range categories {
+		if cat.ParentID != nil && present[*cat.ParentID] {
+			children[*cat.ParentID] = append(children[*cat.ParentID], cat)
+			continue
+		}
+		roots = append(roots, cat)
+	}
+
+	var attach func(nodes []model.CategoryResponse) []model.CategoryResponse
+	attach = func(nodes []model.CategoryResponse) []model.CategoryResponse {
+		for i := range nodes {
+			nodes[i].Children = attach(children[nodes[i].ID])
+		}
+		return nodes
+	}
+	if roots == nil {
+		return []model.CategoryResponse{}
+	}
+	return attach(roots)
+}
+
+func UpdateCategory(c *gin.Context) {
+	var category model.CategoryRequest
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	categoryID := c.Param("id")
+
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND deletedAt IS NULL)", categoryID).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	if category.ParentID != nil {
+		// The new parent must exist and must not be the category itself or
+		// one of its descendants, otherwise the tree would contain a cycle.
+		var parentExists, isDescendant bool
+		err = config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM categories WHERE id = $1 AND deletedAt IS NULL)", *category.ParentID).Scan(&parentExists)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check parent category"})
+			return
+		}
+		if !parentExists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Parent category not found"})
+			return
+		}
+		err = config.DB.QueryRow(`
+    WITH RECURSIVE tree AS (
+        SELECT id FROM categories WHERE id = $1
+        UNION ALL
+        SELECT ch.id FROM categories ch JOIN tree t ON ch.parentId = t.id
+    )
+    SELECT EXISTS(SELECT 1 FROM tree WHERE id = $2)`, categoryID, *category.ParentID).Scan(&isDescendant)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check parent category"})
+			return
+		}
+		if isDescendant {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Category cannot be moved under itself"})
+			return
+		}
+	}
+
+	query := `
+    UPDATE categories
+    SET
+        name = $1,
+        parentId = $2,
+        displayOrder = $3,
+        isActive = $4,
+        updatedAt = NOW()
+    WHERE
+        id = $5
+        AND deletedAt IS NULL
+`
+	_, err = config.DB.Exec(query, category.Name, category.ParentID, category.DisplayOrder, *category.IsActive, categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Category"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Category"})
+}
+
+func DeleteCategory(c *gin.Context) {
+	categoryID := c.Param("id")
+
+	var exists, hasChildren, hasProducts bool
+	err := config.DB.QueryRow(`
+    SELECT
+        EXISTS(SELECT 1 FROM categories WHERE id = $1 AND deletedAt IS NULL),
+        EXISTS(SELECT 1 FROM categories WHERE parentId = $1 AND deletedAt IS NULL),
+        EXISTS(SELECT 1 FROM products WHERE categoryId = $1 AND deletedAt IS NULL)`, categoryID).Scan(&exists, &hasChildren, &hasProducts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	if hasChildren {
+		c.JSON(http.StatusConflict, gin.H{"error": "Category still has subcategories"})
+		return
+	}
+	if hasProducts {
+		c.JSON(http.StatusConflict, gin.H{"error": "Category still has products"})
+		return
+	}
+
+	_, err = config.DB.Exec("UPDATE categories SET deletedAt = NOW() WHERE id = $1 AND deletedAt IS NULL", categoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Category"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Delete Category"})
+}
@@ -0,0 +1,639 @@
+package controller
+
+import (
+	"database/sql"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// requestError is a failure caused by the request itself; it is
+// reported to the client with its own status code.
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+type checkoutLine struct {
+	ProductID string
+	VariantID string
+	Type      string
+	Quantity  int
+	Price     helper.Money
+	Total     helper.Money
+	Discount  helper.Money
+
+	// VariantPriced is set when a variant overrides the product price
+	VariantPriced bool
+
+	// NetTotal is what the customer pays for the line after every discount,
+	// tax included
+	NetTotal helper.Money
+
+	TaxClassID string
+	TaxRate    float64
+	Tax        helper.Money
+
+	// Components is what one unit of a bundle is made of. It is filled in
+	// from the bundle when stock is taken and from the sale when stock is
+	// put back, so later changes to the bundle do not change a sale.
+	Components []bundleComponent
+}
+
+// bundleComponent is a product in a bundle with its quantity per bundle.
+type bundleComponent struct {
+	ProductID int
+	Quantity  int
+}
+
+// stockKey identifies a row whose stock is decremented at checkout.
+type stockKey struct {
+	table string
+	id    int
+}
+
+// buildCheckoutLines resolves the unit price of every requested item at the
+// moment of sale.
+func buildCheckoutLines(tx *sql.Tx, details []model.CheckoutProductDetail) ([]checkoutLine, error) {
+	lines := make([]checkoutLine, 0, len(details))
+	for _, detail := range details {
+		line := checkoutLine{ProductID: detail.ProductID, VariantID: detail.VariantID, Quantity: detail.Quantity}
+
+		var isAvailable, hasVariants bool
+		err := tx.QueryRow(`
+    SELECT p.type, p.price, p.isAvailable, EXISTS(SELECT 1 FROM product_variants v WHERE v.productId = p.id AND v.deletedAt IS NULL)
+    FROM `+productSource+` p
+    WHERE p.id = $1 AND p.deletedAt IS NULL`, detail.ProductID).Scan(&line.Type, &line.Price, &isAvailable, &hasVariants)
+		if err == sql.ErrNoRows {
+			return nil, &requestError{http.StatusNotFound, "Product not found"}
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !isAvailable {
+			return nil, &requestError{http.StatusBadRequest, "Product is not available"}
+		}
+
+		if detail.VariantID != "" {
+			if line.Type == "bundle" {
+				return nil, &requestError{http.StatusBadRequest, "Bundles have no variants"}
+			}
+			err := tx.QueryRow("SELECT COALESCE(price, $1), price IS NOT NULL, isAvailable FROM product_variants WHERE id = $2 AND productId = $3 AND deletedAt IS NULL",
+				line.Price, detail.VariantID, detail.ProductID).Scan(&line.Price, &line.VariantPriced, &isAvailable)
+			if err == sql.ErrNoRows {
+				return nil, &requestError{http.StatusNotFound, "Variant not found"}
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !isAvailable {
+				return nil, &requestError{http.StatusBadRequest, "Variant is not available"}
+			}
+		} else if hasVariants {
+			return nil, &requestError{http.StatusBadRequest, "variantId is required for products with variants"}
+		}
+
+		line.Total = line.Price.Mul(line.Quantity)
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// bundleComponents loads what one unit of a bundle is made of now.
+func bundleComponents(tx *sql.Tx, bundleID string) ([]bundleComponent, error) {
+	rows, err := tx.Query("SELECT productId, quantity FROM bundle_items WHERE bundleId = $1 ORDER BY productId ASC", bundleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	components := []bundleComponent{}
+	for rows.Next() {
+		var component bundleComponent
+		if err := rows.Scan(&component.ProductID, &component.Quantity); err != nil {
+			return nil, err
+		}
+		components = append(components, component)
+	}
+	return components, rows.Err()
+}
+
+// stockQuantities sums the stock the lines move per row, with bundles
+// expanded into their components. Bundle lines without components get the
+// bundle's current ones. Keys are returned in a fixed order so that
+// concurrent updates do not deadlock each other.
+func stockQuantities(tx *sql.Tx, lines []checkoutLine) (map[stockKey]int, []stockKey, error) {
+	quantities := map[stockKey]int{}
+	for i := range lines {
+		line := &lines[i]
+		switch {
+		case line.VariantID != "":
+			id, _ := strconv.Atoi(line.VariantID)
+			quantities[stockKey{"product_variants", id}] += line.Quantity
+		case line.Type == "bundle":
+			if line.Components == nil {
+				components, err := bundleComponents(tx, line.ProductID)
+				if err != nil {
+					return nil, nil, err
+				}
+				line.Components = components
+			}
+			for _, component := range line.Components {
+				quantities[stockKey{"products", component.ProductID}] += component.Quantity * line.Quantity
+			}
+		default:
+			id, _ := strconv.Atoi(line.ProductID)
+			quantities[stockKey{"products", id}] += line.Quantity
+		}
+	}
+
+	keys := make([]stockKey, 0, len(quantities))
+	for key := range quantities {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].table != keys[j].table {
+			return keys[i].table < keys[j].table
+		}
+		return keys[i].id < keys[j].id
+	})
+	return quantities, keys, nil
+}
+
+// versionBump is the SET clause moving a row of table to a new version.
+// Stock is part of a product, so writing it changes the product's ETag.
+func versionBump(table string) string {
+	if table == "products" {
+		return "version = version + 1, "
+	}
+	return ""
+}
+
+// decrementStock takes the sold quantities out of stock. Bundles take their
+// stock from each component. Stock reserved by carts cannot be sold.
+func decrementStock(tx *sql.Tx, lines []checkoutLine) error {
+	quantities, keys, err := stockQuantities(tx, lines)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		result, err := tx.Exec("UPDATE "+key.table+" SET stock = stock - $1, "+versionBump(key.table)+"updatedAt = NOW() WHERE id = $2 AND stock - "+reservedStock(reservationColumn(key.table), "$2")+" >= $1 AND isAvailable = true AND deletedAt IS NULL",
+			quantities[key], key.id)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return &requestError{http.StatusBadRequest, "Insufficient stock"}
+		}
+	}
+	return nil
+}
+
+// restockLines puts returned quantities back into stock. Rows are restocked
+// even when they were deleted or made unavailable since the sale.
+func restockLines(tx *sql.Tx, lines []checkoutLine) error {
+	quantities, keys, err := stockQuantities(tx, lines)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if _, err := tx.Exec("UPDATE "+key.table+" SET stock = stock + $1, "+versionBump(key.table)+"updatedAt = NOW() WHERE id = $2", quantities[key], key.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func customerExists(customerID string) (bool, error) {
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1 AND role = 1 AND deletedAt IS NULL)", customerID).Scan(&exists)
+	return exists, err
+}
+
+// respondRequestError writes err as a client error when it is one and as a
+// generic server error otherwise.
+func respondRequestError(c *gin.Context, err error, fallback string) {
+	if reqErr, ok := err.(*requestError); ok {
+		c.JSON(reqErr.status, gin.H{"error": reqErr.message})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// priceInSaleCurrency resolves the currency of a sale and, when it is not
+// the base currency, re-prices the lines in it.
+func priceInSaleCurrency(tx *sql.Tx, lines []checkoutLine, requested string) (string, *big.Rat, error) {
+	currency, rate, err := resolveSaleCurrency(tx, requested)
+	if err != nil || rate == nil {
+		return currency, rate, err
+	}
+	return currency, rate, priceLinesInCurrency(tx, lines, currency, rate)
+}
+
+// toBase converts a sale amount to the base currency; a nil rate means the
+// sale already is in the base currency.
+func toBase(amount helper.Money, rate *big.Rat) helper.Money {
+	if rate == nil {
+		return amount
+	}
+	return amount.ToBase(rate)
+}
+
+func transactionDetail(line checkoutLine) model.TransactionDetail {
+	return model.TransactionDetail{
+		ProductID: line.ProductID,
+		VariantID: line.VariantID,
+		Quantity:  line.Quantity,
+		Price:     line.Price,
+		Total:     line.Total,
+		Discount:  line.Discount,
+		TaxRate:   line.TaxRate,
+		Tax:       line.Tax,
+	}
+}
+
+// CheckoutQuote prices a basket the way Checkout would, promotions included,
+// without selling anything.
+func CheckoutQuote(c *gin.Context) {
+	var request model.CheckoutQuoteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Quote Checkout"})
+		return
+	}
+	defer tx.Rollback()
+
+	lines, err := buildCheckoutLines(tx, request.ProductDetails)
+	if err != nil {
+		respondRequestError(c, err, "Error when Quote Checkout")
+		return
+	}
+	currency, rate, err := priceInSaleCurrency(tx, lines, request.Currency)
+	if err != nil {
+		respondRequestError(c, err, "Error when Quote Checkout")
+		return
+	}
+	breakdown, err := applyPromotions(tx, lines, request.PromoCode, rate)
+	if err != nil {
+		respondRequestError(c, err, "Error when Quote Checkout")
+		return
+	}
+	taxes, err := applyTax(tx, lines, breakdown)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Quote Checkout"})
+		return
+	}
+	details := make([]model.TransactionDetail, 0, len(lines))
+	for _, line := range lines {
+		details = append(details, transactionDetail(line))
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data": model.CheckoutQuoteResponse{
+			ProductDetails: details,
+			Discounts:      appliedDiscounts(lines, breakdown.Discounts),
+			Subtotal:       breakdown.Subtotal,
+			Discount:       breakdown.DiscountTotal,
+			Tax:            taxes.Tax,
+			TaxMode:        taxMode(taxRules()),
+			Total:          taxes.Total,
+			Currency:       currency,
+			ExchangeRate:   formatExchangeRate(rate),
+			BaseTotal:      toBase(taxes.Total, rate),
+		},
+	})
+}
+
+func Checkout(c *gin.Context) {
+	var request model.CheckoutRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if (request.CartID == "") == (len(request.ProductDetails) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either productDetails or cartId is required"})
+		return
+	}
+
+	exists, err := customerExists(request.CustomerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check customer"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+		return
+	}
+	defer tx.Rollback()
+
+	productDetails := request.ProductDetails
+	if request.CartID != "" {
+		productDetails, err = checkoutCart(tx, request.CartID, request.CustomerID)
+		if err != nil {
+			respondRequestError(c, err, "Error when Checkout")
+			return
+		}
+	}
+	lines, err := buildCheckoutLines(tx, productDetails)
+	if err != nil {
+		respondRequestError(c, err, "Error when Checkout")
+		return
+	}
+	currency, rate, err := priceInSaleCurrency(tx, lines, request.Currency)
+	if err != nil {
+		respondRequestError(c, err, "Error when Checkout")
+		return
+	}
+	breakdown, err := applyPromotions(tx, lines, request.PromoCode, rate)
+	if err != nil {
+		respondRequestError(c, err, "Error when Checkout")
+		return
+	}
+	taxes, err := applyTax(tx, lines, breakdown)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+		return
+	}
+	mode := taxMode(taxRules())
+	total := taxes.Total
+
+	payments, paid, change, err := settlePayments(request, total)
+	if err != nil {
+		respondRequestError(c, err, "Error when Checkout")
+		return
+	}
+
+	if err := decrementStock(tx, lines); err != nil {
+		respondRequestError(c, err, "Error when Checkout")
+		return
+	}
+	if err := redeemPromotions(tx, breakdown.Discounts); err != nil {
+		respondRequestError(c, err, "Error when Checkout")
+		return
+	}
+
+	var transactionID int
+	var createdAt time.Time
+	exchangeRate := formatExchangeRate(rate)
+	baseTotal, basePaid, baseChange := toBase(total, rate), toBase(paid, rate), toBase(change, rate)
+	err = tx.QueryRow(`
+    INSERT INTO transactions (customerId, staffId, subtotal, discount, tax, taxMode, total, paid, change, currency, exchangeRate, baseTotal, basePaid, baseChange)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+    RETURNING id, createdAt`,
+		request.CustomerID, c.GetInt("userId"), breakdown.Subtotal, breakdown.DiscountTotal, taxes.Tax, mode, total, paid, change,
+		currency, exchangeRate.String(), baseTotal, basePaid, baseChange).Scan(&transactionID, &createdAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+		return
+	}
+	if request.CartID != "" {
+		_, err := tx.Exec("UPDATE carts SET status = 'checked_out', transactionId = $1, updatedAt = NOW() WHERE id = $2", transactionID, request.CartID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+			return
+		}
+	}
+	details := make([]model.TransactionDetail, 0, len(lines))
+	for _, line := range lines {
+		var variantID, taxClassID interface{}
+		if line.VariantID != "" {
+			variantID = line.VariantID
+		}
+		if line.TaxClassID != "" {
+			taxClassID = line.TaxClassID
+		}
+		var detailID int
+		err := tx.QueryRow("INSERT INTO transaction_details (transactionId, productId, variantId, quantity, price, total, discount, taxClassId, taxRate, tax, netTotal) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id",
+			transactionID, line.ProductID, variantID, line.Quantity, line.Price, line.Total, line.Discount, taxClassID, line.TaxRate, line.Tax, line.NetTotal).Scan(&detailID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+			return
+		}
+		for _, component := range line.Components {
+			_, err := tx.Exec("INSERT INTO transaction_detail_components (transactionDetailId, productId, quantity) VALUES ($1, $2, $3)",
+				detailID, component.ProductID, component.Quantity)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+				return
+			}
+		}
+		details = append(details, transactionDetail(line))
+	}
+	discounts := appliedDiscounts(lines, breakdown.Discounts)
+	for _, d := range discounts {
+		var productID, variantID interface{}
+		if d.ProductID != "" {
+			productID = d.ProductID
+		}
+		if d.VariantID != "" {
+			variantID = d.VariantID
+		}
+		_, err := tx.Exec("INSERT INTO transaction_discounts (transactionId, promotionId, productId, variantId, amount) VALUES ($1, $2, $3, $4, $5)",
+			transactionID, d.PromotionID, productID, variantID, d.Amount)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+			return
+		}
+	}
+	recordedPayments, err := recordPayments(tx, transactionID, request.CustomerID, payments, rate)
+	if err != nil {
+		respondRequestError(c, err, "Error when Checkout")
+		return
+	}
+	if err := earnPoints(tx, transactionID, request.CustomerID, recordedPayments, baseTotal); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Checkout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data": model.TransactionResponse{
+			TransactionID:  strconv.Itoa(transactionID),
+			CustomerID:     request.CustomerID,
+			ProductDetails: details,
+			Discounts:      discounts,
+			Subtotal:       breakdown.Subtotal,
+			Discount:       breakdown.DiscountTotal,
+			Tax:            taxes.Tax,
+			TaxMode:        mode,
+			Total:          total,
+			Payments:       recordedPayments,
+			Paid:           paid,
+			Change:         change,
+			Currency:       currency,
+			ExchangeRate:   exchangeRate,
+			BaseTotal:      baseTotal,
+			BasePaid:       basePaid,
+			BaseChange:     baseChange,
+			RefundStatus:   "none",
+			CreatedAt:      createdAt,
+		},
+	})
+}
+
+// attachTransactionDetails loads the line items, applied discounts and
+// payments of every transaction.
+func attachTransactionDetails(transactions []model.TransactionResponse) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+	ids := make([]string, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.TransactionID
+	}
+
+	rows, err := config.DB.Query(`
+    SELECT transactionId, productId, COALESCE(variantId::text, ''), quantity, price, total, discount, taxRate, tax
+    FROM transaction_details
+    WHERE transactionId = ANY($1::int[])
+    ORDER BY id ASC`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	details := map[string][]model.TransactionDetail{}
+	for rows.Next() {
+		var transactionID string
+		var d model.TransactionDetail
+		if err := rows.Scan(&transactionID, &d.ProductID, &d.VariantID, &d.Quantity, &d.Price, &d.Total, &d.Discount, &d.TaxRate, &d.Tax); err != nil {
+			return err
+		}
+		details[transactionID] = append(details[transactionID], d)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	discountRows, err := config.DB.Query(`
+    SELECT td.transactionId, td.promotionId, pm.name, COALESCE(td.productId::text, ''), COALESCE(td.variantId::text, ''), td.amount
+    FROM transaction_discounts td
+    JOIN promotions pm ON pm.id = td.promotionId
+    WHERE td.transactionId = ANY($1::int[])
+    ORDER BY td.id ASC`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer discountRows.Close()
+	discounts := map[string][]model.AppliedDiscount{}
+	for discountRows.Next() {
+		var transactionID string
+		var d model.AppliedDiscount
+		if err := discountRows.Scan(&transactionID, &d.PromotionID, &d.Name, &d.ProductID, &d.VariantID, &d.Amount); err != nil {
+			return err
+		}
+		discounts[transactionID] = append(discounts[transactionID], d)
+	}
+	if err := discountRows.Err(); err != nil {
+		return err
+	}
+
+	payments, err := getTransactionPayments(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range transactions {
+		transactions[i].ProductDetails = details[transactions[i].TransactionID]
+		transactions[i].Payments = payments[transactions[i].TransactionID]
+		transactions[i].Discounts = discounts[transactions[i].TransactionID]
+		if transactions[i].Discounts == nil {
+			transactions[i].Discounts = []model.AppliedDiscount{}
+		}
+	}
+	return nil
+}
+
+const transactionColumns = "id, customerId, subtotal, discount, tax, taxMode, total, paid, change, currency, rtrim(rtrim(exchangeRate::text, '0'), '.'), baseTotal, basePaid, baseChange, refundStatus, voidedAt, COALESCE(voidReason, ''), createdAt"
+
+// scanTransaction reads the transactionColumns of a row with scan, which is
+// the Scan method of a *sql.Row or *sql.Rows.
+func scanTransaction(scan func(dest ...interface{}) error) (model.TransactionResponse, error) {
+	var t model.TransactionResponse
+	err := scan(&t.TransactionID, &t.CustomerID, &t.Subtotal, &t.Discount, &t.Tax, &t.TaxMode, &t.Total, &t.Paid, &t.Change, &t.Currency, &t.ExchangeRate, &t.BaseTotal, &t.BasePaid, &t.BaseChange, &t.RefundStatus, &t.VoidedAt, &t.VoidReason, &t.CreatedAt)
+	return t, err
+}
+
+// queryTransactions loads a page of transactions, with their details,
+// optionally of a single customer.
+func queryTransactions(params model.GetTransactionParams) ([]model.TransactionResponse, error) {
+	query := "SELECT " + transactionColumns + " FROM transactions WHERE 1=1"
+	args := []interface{}{}
+	if params.CustomerID != "" {
+		query += " AND customerId = $" + strconv.Itoa(len(args)+1)
+		args = append(args, params.CustomerID)
+	}
+	if params.CreatedAt == "asc" {
+		query += " ORDER BY createdAt ASC"
+	} else {
+		query += " ORDER BY createdAt DESC"
+	}
+	query += " LIMIT $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Limit)
+	query += " OFFSET $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Offset)
+
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	transactions := []model.TransactionResponse{}
+	for rows.Next() {
+		t, err := scanTransaction(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := attachTransactionDetails(transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+func GetCheckoutHistory(c *gin.Context) {
+	var params model.GetTransactionParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	transactions, err := queryTransactions(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve History"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    transactions,
+	})
+}
@@ -0,0 +1,293 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+func baseCurrency() string {
+	return strings.ToUpper(config.GetEnv("BASE_CURRENCY", "IDR"))
+}
+
+func isCurrencyCode(code string) bool {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	return ok && validate.Var(code, "required,iso4217") == nil
+}
+
+// formatExchangeRate writes a rate with the precision it is stored with.
+func formatExchangeRate(rate *big.Rat) json.Number {
+	if rate == nil {
+		return json.Number("1")
+	}
+	return json.Number(strings.TrimRight(strings.TrimRight(rate.FloatString(8), "0"), "."))
+}
+
+// resolveSaleCurrency returns the currency a sale is priced in and its rate
+// to the base currency in effect now. The rate is nil for the base currency
+// itself.
+func resolveSaleCurrency(db dbExecutor, currency string) (string, *big.Rat, error) {
+	currency = strings.ToUpper(currency)
+	if currency == "" || currency == baseCurrency() {
+		return baseCurrency(), nil, nil
+	}
+
+	var rate string
+	err := db.QueryRow(`
+    SELECT rate::text FROM exchange_rates
+    WHERE currency = $1 AND effectiveFrom <= NOW()
+    ORDER BY effectiveFrom DESC, id DESC
+    LIMIT 1`, currency).Scan(&rate)
+	if err == sql.ErrNoRows {
+		return "", nil, &requestError{http.StatusBadRequest, "No exchange rate for currency"}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	parsed, err := helper.ParseExchangeRate(rate)
+	if err != nil {
+		return "", nil, err
+	}
+	return currency, parsed, nil
+}
+
+// priceLinesInCurrency re-prices checkout lines, resolved in the base
+// currency, in currency. A product's price list entry for the currency is
+// used when it has one, otherwise the base price is converted at rate.
+// Variants with a price of their own are always converted.
+func priceLinesInCurrency(db dbExecutor, lines []checkoutLine, currency string, rate *big.Rat) error {
+	productIDs := make([]string, len(lines))
+	for i, line := range lines {
+		productIDs[i] = line.ProductID
+	}
+	rows, err := db.Query("SELECT productId::text, price FROM product_currency_prices WHERE productId = ANY($1::int[]) AND currency = $2", pq.Array(productIDs), currency)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	listed := map[string]helper.Money{}
+	for rows.Next() {
+		var productID string
+		var price helper.Money
+		if err := rows.Scan(&productID, &price); err != nil {
+			return err
+		}
+		listed[productID] = price
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, line := range lines {
+		if price, ok := listed[line.ProductID]; ok && !line.VariantPriced {
+			lines[i].Price = price
+		} else {
+			lines[i].Price = line.Price.FromBase(rate)
+		}
+		lines[i].Total = lines[i].Price.Mul(line.Quantity)
+	}
+	return nil
+}
+
+func AddExchangeRate(c *gin.Context) {
+	var request model.ExchangeRateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	currency := strings.ToUpper(request.Currency)
+	if currency == baseCurrency() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Base currency has no exchange rate"})
+		return
+	}
+	if _, err := helper.ParseExchangeRate(request.Rate.String()); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rate must be a positive number"})
+		return
+	}
+
+	// As with prices, a rate may not take effect in the past so the rate of
+	// a recorded sale never changes.
+	var lastInsertedID int
+	err := config.DB.QueryRow(`
+    INSERT INTO exchange_rates (currency, rate, effectiveFrom, createdBy)
+    SELECT $1, $2, COALESCE($3::timestamptz, NOW()), $4
+    WHERE COALESCE($3::timestamptz, NOW()) >= NOW()
+    RETURNING id`,
+		currency, request.Rate.String(), request.EffectiveFrom, c.GetInt("userId")).Scan(&lastInsertedID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rate cannot take effect in the past"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Exchange Rate"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Exchange rate added successfully",
+		"data":    gin.H{"id": strconv.Itoa(lastInsertedID)},
+	})
+}
+
+func GetExchangeRates(c *gin.Context) {
+	var params model.GetExchangeRateParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	query := `
+    SELECT er.id, er.currency, er.rate::text, er.effectiveFrom, er.createdAt,
+        CASE
+            WHEN er.effectiveFrom > NOW() THEN 'scheduled'
+            WHEN er.id = (
+                SELECT cur.id FROM exchange_rates cur
+                WHERE cur.currency = er.currency AND cur.effectiveFrom <= NOW()
+                ORDER BY cur.effectiveFrom DESC, cur.id DESC
+                LIMIT 1
+            ) THEN 'active'
+            ELSE 'superseded'
+        END
+    FROM exchange_rates er WHERE 1=1`
+	args := []interface{}{}
+	if params.Currency != "" {
+		query += " AND er.currency = $" + strconv.Itoa(len(args)+1)
+		args = append(args, strings.ToUpper(params.Currency))
+	}
+	query += " ORDER BY er.effectiveFrom DESC, er.id DESC"
+	query += " LIMIT $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Limit)
+	query += " OFFSET $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Offset)
+
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Exchange Rates"})
+		return
+	}
+	defer rows.Close()
+	rates := []model.ExchangeRateResponse{}
+	for rows.Next() {
+		var r model.ExchangeRateResponse
+		var rate string
+		if err := rows.Scan(&r.ID, &r.Currency, &rate, &r.EffectiveFrom, &r.CreatedAt, &r.Status); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Exchange Rates"})
+			return
+		}
+		parsed, err := helper.ParseExchangeRate(rate)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Exchange Rates"})
+			return
+		}
+		r.Rate = formatExchangeRate(parsed)
+		rates = append(rates, r)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Exchange Rates"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    rates,
+	})
+}
+
+func SetProductCurrencyPrice(c *gin.Context) {
+	var request model.CurrencyPriceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	productID := c.Param("id")
+	currency := strings.ToUpper(c.Param("currency"))
+	if !isCurrencyCode(currency) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid currency"})
+		return
+	}
+	if currency == baseCurrency() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use the product price for the base currency"})
+		return
+	}
+
+	exists, err := productExists(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	_, err = config.DB.Exec(`
+    INSERT INTO product_currency_prices (productId, currency, price) VALUES ($1, $2, $3)
+    ON CONFLICT (productId, currency) DO UPDATE SET price = EXCLUDED.price, updatedAt = NOW()`,
+		productID, currency, request.Price)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Set Currency Price"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Set Currency Price"})
+}
+
+func GetProductCurrencyPrices(c *gin.Context) {
+	productID := c.Param("id")
+
+	exists, err := productExists(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	rows, err := config.DB.Query("SELECT productId, currency, price, updatedAt FROM product_currency_prices WHERE productId = $1 ORDER BY currency ASC", productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Currency Prices"})
+		return
+	}
+	defer rows.Close()
+	prices := []model.CurrencyPriceResponse{}
+	for rows.Next() {
+		var p model.CurrencyPriceResponse
+		if err := rows.Scan(&p.ProductID, &p.Currency, &p.Price, &p.UpdatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Currency Prices"})
+			return
+		}
+		prices = append(prices, p)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Currency Prices"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    prices,
+	})
+}
+
+func DeleteProductCurrencyPrice(c *gin.Context) {
+	result, err := config.DB.Exec("DELETE FROM product_currency_prices WHERE productId = $1 AND currency = $2",
+		c.Param("id"), strings.ToUpper(c.Param("currency")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Currency Price"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Currency price not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Delete Currency Price"})
+}
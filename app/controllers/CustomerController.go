@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// nullString stores an empty optional field as NULL.
+func nullString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// customerStats sums up the purchases of a customer.
+func customerStats(customerID string) (model.CustomerStats, error) {
+	var stats model.CustomerStats
+	var firstVisitAt, lastVisitAt sql.NullTime
+	err := config.DB.QueryRow(`
+    SELECT COUNT(*),
+        COALESCE(SUM(t.baseTotal), 0) - COALESCE((
+            SELECT SUM(r.baseAmount) FROM refunds r
+            JOIN transactions rt ON rt.id = r.transactionId
+            WHERE rt.customerId = $1), 0),
+        MIN(t.createdAt), MAX(t.createdAt)
+    FROM transactions t
+    WHERE t.customerId = $1 AND t.voidedAt IS NULL`, customerID).Scan(&stats.VisitCount, &stats.LifetimeValue, &firstVisitAt, &lastVisitAt)
+	if err != nil {
+		return stats, err
+	}
+	if stats.VisitCount > 0 {
+		stats.AverageBasket = stats.LifetimeValue.Share(1, helper.Money(stats.VisitCount))
+	}
+	if firstVisitAt.Valid {
+		stats.FirstVisitAt = &firstVisitAt.Time
+	}
+	if lastVisitAt.Valid {
+		stats.LastVisitAt = &lastVisitAt.Time
+	}
+	return stats, nil
+}
+
+// getCustomerProfile loads a customer with their profile and purchase
+// stats.
+func getCustomerProfile(customerID string) (model.CustomerProfileResponse, error) {
+	var p model.CustomerProfileResponse
+	var birthday, consentAt sql.NullTime
+	err := config.DB.QueryRow(`
+    SELECT u.id, u.phoneNumber, u.name, COALESCE(cp.email, ''), cp.birthday, COALESCE(cp.address, ''),
+        COALESCE(cp.marketingConsent, false), cp.marketingConsentAt, u.createdAt
+    FROM users u
+    LEFT JOIN customer_profiles cp ON cp.customerId = u.id
+    WHERE u.id = $1 AND u.role = 1 AND u.deletedAt IS NULL`, customerID).Scan(
+		&p.UserId, &p.PhoneNumber, &p.Name, &p.Email, &birthday, &p.Address, &p.MarketingConsent, &consentAt, &p.CreatedAt)
+	if err != nil {
+		return p, err
+	}
+	if birthday.Valid {
+		p.Birthday = birthday.Time.Format("2006-01-02")
+	}
+	if consentAt.Valid {
+		p.MarketingConsentAt = &consentAt.Time
+	}
+	p.Stats, err = customerStats(customerID)
+	return p, err
+}
+
+func GetCustomerProfile(c *gin.Context) {
+	customerID := c.Param("id")
+	if _, err := strconv.Atoi(customerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	profile, err := getCustomerProfile(customerID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Customer"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    profile,
+	})
+}
+
+// UpdateCustomerProfile replaces a customer's name and profile. The time
+// of consent is kept until marketing consent changes.
+func UpdateCustomerProfile(c *gin.Context) {
+	var request model.CustomerProfileRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	customerID := c.Param("id")
+	if _, err := strconv.Atoi(customerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	if request.Birthday != "" {
+		birthday, _ := time.Parse("2006-01-02", request.Birthday)
+		if birthday.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Birthday cannot be in the future"})
+			return
+		}
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Customer"})
+		return
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("UPDATE users SET name = $1, updatedAt = NOW() WHERE id = $2 AND role = 1 AND deletedAt IS NULL", request.Name, customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Customer"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	_, err = tx.Exec(`
+    INSERT INTO customer_profiles (customerId, email, birthday, address, marketingConsent, marketingConsentAt, updatedBy)
+    VALUES ($1, $2, $3, $4, $5, CASE WHEN $5 THEN NOW() END, $6)
+    ON CONFLICT (customerId) DO UPDATE SET
+        email = EXCLUDED.email,
+        birthday = EXCLUDED.birthday,
+        address = EXCLUDED.address,
+        marketingConsentAt = CASE
+            WHEN customer_profiles.marketingConsent = EXCLUDED.marketingConsent THEN customer_profiles.marketingConsentAt
+            ELSE NOW() END,
+        marketingConsent = EXCLUDED.marketingConsent,
+        updatedBy = EXCLUDED.updatedBy,
+        updatedAt = NOW()`,
+		customerID, nullString(request.Email), nullString(request.Birthday), nullString(request.Address), request.MarketingConsent, c.GetInt("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Customer"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Customer"})
+		return
+	}
+
+	profile, err := getCustomerProfile(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Customer"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully Update Customer",
+		"data":    profile,
+	})
+}
+
+// GetCustomerTransactions is the purchase history of a customer, paginated
+// and ordered like the checkout history.
+func GetCustomerTransactions(c *gin.Context) {
+	var params model.GetTransactionParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	customerID := c.Param("id")
+	if _, err := strconv.Atoi(customerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	exists, err := customerExists(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check customer"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	params.CustomerID = customerID
+	transactions, err := queryTransactions(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve History"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    transactions,
+	})
+}
@@ -0,0 +1,341 @@
+package controller
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// giftCardAlphabet leaves out characters that are easily misread
+const giftCardAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// normalizeGiftCardCode lets a code be typed in any case and grouped with
+// dashes or spaces.
+func normalizeGiftCardCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return strings.ToUpper(code)
+}
+
+func validGiftCardCode(code string) bool {
+	if len(code) < 8 || len(code) > 32 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func generateGiftCardCode() (string, error) {
+	code := make([]byte, 16)
+	max := big.NewInt(int64(len(giftCardAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = giftCardAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+// maskGiftCardCode shows only the last four characters of a code, for
+// receipts.
+func maskGiftCardCode(code string) string {
+	if len(code) <= 4 {
+		return code
+	}
+	return strings.Repeat("*", len(code)-4) + code[len(code)-4:]
+}
+
+// paymentReference is the reference of a tender as shown to clients. Gift
+// card codes are masked, as whoever knows a code can spend its balance.
+func paymentReference(method, reference string) string {
+	if method == "gift_card" {
+		return maskGiftCardCode(normalizeGiftCardCode(reference))
+	}
+	return reference
+}
+
+// changeGiftCardBalance moves a gift card or store credit balance by
+// amount and records the change in the ledger.
+func changeGiftCardBalance(tx *sql.Tx, giftCardID int, entryType string, amount helper.Money, transactionID, refundID interface{}) error {
+	var balance helper.Money
+	err := tx.QueryRow("UPDATE gift_cards SET balance = balance + $1, updatedAt = NOW() WHERE id = $2 RETURNING balance", amount, giftCardID).Scan(&balance)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO gift_card_ledger (giftCardId, type, amount, balance, transactionId, refundId) VALUES ($1, $2, $3, $4, $5, $6)",
+		giftCardID, entryType, amount, balance, transactionID, refundID)
+	return err
+}
+
+// redeemGiftCard pays baseAmount from the gift card with the given code.
+// A card can be used for part of a sale and again later for the rest of
+// its balance.
+func redeemGiftCard(tx *sql.Tx, transactionID int, code string, baseAmount helper.Money) error {
+	var giftCardID int
+	var balance helper.Money
+	var expired bool
+	err := tx.QueryRow("SELECT id, balance, COALESCE(expiresAt <= NOW(), false) FROM gift_cards WHERE code = $1 AND kind = 'gift_card' FOR UPDATE",
+		normalizeGiftCardCode(code)).Scan(&giftCardID, &balance, &expired)
+	if err == sql.ErrNoRows {
+		return &requestError{http.StatusBadRequest, "Gift card not found"}
+	}
+	if err != nil {
+		return err
+	}
+	if expired {
+		return &requestError{http.StatusBadRequest, "Gift card has expired"}
+	}
+	if balance < baseAmount {
+		return &requestError{http.StatusBadRequest, "Gift card balance is not enough"}
+	}
+	return changeGiftCardBalance(tx, giftCardID, "redeem", -baseAmount, transactionID, nil)
+}
+
+// redeemStoreCredit pays baseAmount from the customer's store credit.
+func redeemStoreCredit(tx *sql.Tx, transactionID int, customerID string, baseAmount helper.Money) error {
+	var giftCardID int
+	var balance helper.Money
+	err := tx.QueryRow("SELECT id, balance FROM gift_cards WHERE customerId = $1 AND kind = 'store_credit' FOR UPDATE", customerID).Scan(&giftCardID, &balance)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == sql.ErrNoRows || balance < baseAmount {
+		return &requestError{http.StatusBadRequest, "Store credit balance is not enough"}
+	}
+	return changeGiftCardBalance(tx, giftCardID, "redeem", -baseAmount, transactionID, nil)
+}
+
+// creditStoreCredit adds a refund to the customer's store credit, opening
+// it on the first refund.
+func creditStoreCredit(tx *sql.Tx, customerID string, transactionID string, refundID int, baseAmount helper.Money) error {
+	_, err := tx.Exec(`
+    INSERT INTO gift_cards (kind, customerId, initialBalance, balance)
+    VALUES ('store_credit', $1, 0, 0)
+    ON CONFLICT (customerId) WHERE kind = 'store_credit' DO NOTHING`, customerID)
+	if err != nil {
+		return err
+	}
+	var giftCardID int
+	err = tx.QueryRow("SELECT id FROM gift_cards WHERE customerId = $1 AND kind = 'store_credit' FOR UPDATE", customerID).Scan(&giftCardID)
+	if err != nil {
+		return err
+	}
+	return changeGiftCardBalance(tx, giftCardID, "refund", baseAmount, transactionID, refundID)
+}
+
+// reverseVoidGiftCards gives back what a voided sale took from gift cards
+// and store credit.
+func reverseVoidGiftCards(tx *sql.Tx, transactionID string) error {
+	rows, err := tx.Query("SELECT giftCardId, -amount FROM gift_card_ledger WHERE transactionId = $1 AND type = 'redeem' ORDER BY id ASC", transactionID)
+	if err != nil {
+		return err
+	}
+	type redemption struct {
+		giftCardID int
+		amount     helper.Money
+	}
+	var redemptions []redemption
+	for rows.Next() {
+		var r redemption
+		if err := rows.Scan(&r.giftCardID, &r.amount); err != nil {
+			rows.Close()
+			return err
+		}
+		redemptions = append(redemptions, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, r := range redemptions {
+		if err := changeGiftCardBalance(tx, r.giftCardID, "void", r.amount, transactionID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// giftCardHistory loads the ledger of a gift card, newest first.
+func giftCardHistory(giftCardID string) ([]model.GiftCardEntry, error) {
+	rows, err := config.DB.Query(`
+    SELECT id, type, amount, balance, COALESCE(transactionId::text, ''), COALESCE(refundId::text, ''), createdAt
+    FROM gift_card_ledger
+    WHERE giftCardId = $1
+    ORDER BY id DESC`, giftCardID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	history := []model.GiftCardEntry{}
+	for rows.Next() {
+		var e model.GiftCardEntry
+		if err := rows.Scan(&e.ID, &e.Type, &e.Amount, &e.Balance, &e.TransactionID, &e.RefundID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, e)
+	}
+	return history, rows.Err()
+}
+
+const giftCardColumns = "id, COALESCE(code, ''), kind, COALESCE(customerId::text, ''), initialBalance, balance, expiresAt, createdAt"
+
+func scanGiftCard(scan func(dest ...interface{}) error) (model.GiftCardResponse, error) {
+	var g model.GiftCardResponse
+	var expiresAt sql.NullTime
+	if err := scan(&g.ID, &g.Code, &g.Kind, &g.CustomerID, &g.InitialBalance, &g.Balance, &expiresAt, &g.CreatedAt); err != nil {
+		return g, err
+	}
+	if expiresAt.Valid {
+		t := expiresAt.Time
+		g.ExpiresAt = &t
+	}
+	return g, nil
+}
+
+func IssueGiftCard(c *gin.Context) {
+	var request model.GiftCardRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expiry must be in the future"})
+		return
+	}
+	code := normalizeGiftCardCode(request.Code)
+	if code == "" {
+		var err error
+		if code, err = generateGiftCardCode(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Gift Card"})
+			return
+		}
+	}
+	if !validGiftCardCode(code) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gift card code must be 8 to 32 letters or digits"})
+		return
+	}
+
+	var exists bool
+	if err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM gift_cards WHERE code = $1)", code).Scan(&exists); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Gift Card"})
+		return
+	}
+	if exists {
+		c.JSON(http.StatusConflict, gin.H{"error": "Gift card code already exists"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Gift Card"})
+		return
+	}
+	defer tx.Rollback()
+
+	giftCard, err := scanGiftCard(tx.QueryRow(`
+    INSERT INTO gift_cards (code, kind, initialBalance, balance, expiresAt, issuedBy)
+    VALUES ($1, 'gift_card', $2, 0, $3, $4)
+    RETURNING `+giftCardColumns, code, request.InitialBalance, request.ExpiresAt, c.GetInt("userId")).Scan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Gift Card"})
+		return
+	}
+	giftCardID, _ := strconv.Atoi(giftCard.ID)
+	if err := changeGiftCardBalance(tx, giftCardID, "issue", request.InitialBalance, nil, nil); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Gift Card"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Gift Card"})
+		return
+	}
+	giftCard.Balance = request.InitialBalance
+	if giftCard.History, err = giftCardHistory(giftCard.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Gift Card"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Gift card issued successfully",
+		"data":    giftCard,
+	})
+}
+
+// GetGiftCard is the balance inquiry of a gift card.
+func GetGiftCard(c *gin.Context) {
+	giftCard, err := scanGiftCard(config.DB.QueryRow("SELECT "+giftCardColumns+" FROM gift_cards WHERE code = $1 AND kind = 'gift_card'",
+		normalizeGiftCardCode(c.Param("code"))).Scan)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Gift card not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Gift Card"})
+		return
+	}
+	if giftCard.History, err = giftCardHistory(giftCard.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Gift Card"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    giftCard,
+	})
+}
+
+// GetCustomerStoreCredit is the balance inquiry of a customer's store
+// credit. A customer who never had store credit has a zero balance.
+func GetCustomerStoreCredit(c *gin.Context) {
+	customerID := c.Param("id")
+	if _, err := strconv.Atoi(customerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	exists, err := customerExists(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check customer"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	credit, err := scanGiftCard(config.DB.QueryRow("SELECT "+giftCardColumns+" FROM gift_cards WHERE customerId = $1 AND kind = 'store_credit'", customerID).Scan)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Success",
+			"data": model.GiftCardResponse{
+				Kind:       "store_credit",
+				CustomerID: customerID,
+				History:    []model.GiftCardEntry{},
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Store Credit"})
+		return
+	}
+	if credit.History, err = giftCardHistory(credit.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Store Credit"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    credit,
+	})
+}
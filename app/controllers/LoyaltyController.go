@@ -0,0 +1,271 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// pointsAmount reads a base currency amount of the loyalty scheme from the
+// environment. A missing or invalid setting falls back to def.
+func pointsAmount(key string, def helper.Money) helper.Money {
+	amount, err := helper.ParseMoney(config.GetEnv(key, ""))
+	if err != nil || amount <= 0 {
+		return def
+	}
+	return amount
+}
+
+// pointsEarnAmount is how much has to be spent, in the base currency, to
+// earn one point.
+func pointsEarnAmount() helper.Money {
+	return pointsAmount("POINTS_EARN_AMOUNT", 1000000)
+}
+
+// pointsRedeemValue is what one point is worth, in the base currency, when
+// paying with points.
+func pointsRedeemValue() helper.Money {
+	return pointsAmount("POINTS_REDEEM_VALUE", 10000)
+}
+
+// pointsBalance is what a customer can redeem: the unused points of every
+// credit that has not expired yet.
+func pointsBalance(exec dbExecutor, customerID string) (int, error) {
+	var balance int
+	err := exec.QueryRow(`
+    SELECT COALESCE(SUM(remaining), 0) FROM points_ledger
+    WHERE customerId = $1 AND remaining > 0 AND (expiresAt IS NULL OR expiresAt > NOW())`, customerID).Scan(&balance)
+	return balance, err
+}
+
+// creditPoints adds points to a customer's balance. They expire after
+// POINTS_EXPIRY_DAYS; 0 keeps them forever.
+func creditPoints(tx *sql.Tx, customerID string, transactionID, refundID interface{}, kind string, points int) error {
+	if points <= 0 {
+		return nil
+	}
+	_, err := tx.Exec(`
+    INSERT INTO points_ledger (customerId, transactionId, refundId, type, points, remaining, expiresAt)
+    VALUES ($1, $2, $3, $4, $5, $5, CASE WHEN $6 > 0 THEN NOW() + make_interval(days => $6) END)`,
+		customerID, transactionID, refundID, kind, points, config.GetEnvInt("POINTS_EXPIRY_DAYS", 365))
+	return err
+}
+
+// debitPoints takes up to points from a customer's balance, using the
+// credits that expire first, and records the movement. It returns how many
+// points were taken.
+func debitPoints(tx *sql.Tx, customerID string, transactionID, refundID interface{}, kind string, points int) (int, error) {
+	if points <= 0 {
+		return 0, nil
+	}
+	rows, err := tx.Query(`
+    SELECT id, remaining FROM points_ledger
+    WHERE customerId = $1 AND remaining > 0 AND (expiresAt IS NULL OR expiresAt > NOW())
+    ORDER BY expiresAt ASC NULLS LAST, id ASC
+    FOR UPDATE`, customerID)
+	if err != nil {
+		return 0, err
+	}
+	type credit struct{ id, remaining int }
+	var credits []credit
+	for rows.Next() {
+		var cr credit
+		if err := rows.Scan(&cr.id, &cr.remaining); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		credits = append(credits, cr)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	taken := 0
+	for _, cr := range credits {
+		if taken == points {
+			break
+		}
+		take := cr.remaining
+		if take > points-taken {
+			take = points - taken
+		}
+		if _, err := tx.Exec("UPDATE points_ledger SET remaining = remaining - $1 WHERE id = $2", take, cr.id); err != nil {
+			return 0, err
+		}
+		taken += take
+	}
+	if taken == 0 {
+		return 0, nil
+	}
+	_, err = tx.Exec("INSERT INTO points_ledger (customerId, transactionId, refundId, type, points) VALUES ($1, $2, $3, $4, $5)",
+		customerID, transactionID, refundID, kind, -taken)
+	return taken, err
+}
+
+// redeemPoints pays baseAmount with the customer's points, rounded up to
+// whole points.
+func redeemPoints(tx *sql.Tx, transactionID int, customerID string, baseAmount helper.Money) error {
+	value := pointsRedeemValue()
+	points := int((baseAmount + value - 1) / value)
+	taken, err := debitPoints(tx, customerID, transactionID, nil, "redeem", points)
+	if err != nil {
+		return err
+	}
+	if taken < points {
+		return &requestError{http.StatusBadRequest, "Not enough points"}
+	}
+	return nil
+}
+
+// earnPoints credits the points a sale earns. Only what was spent in other
+// tenders than points counts.
+func earnPoints(tx *sql.Tx, transactionID int, customerID string, payments []model.TransactionPayment, baseTotal helper.Money) error {
+	spent := baseTotal
+	for _, payment := range payments {
+		if payment.Method == "points" {
+			spent -= payment.BaseAmount
+		}
+	}
+	if spent <= 0 {
+		return nil
+	}
+	return creditPoints(tx, customerID, transactionID, nil, "earn", int(spent/pointsEarnAmount()))
+}
+
+// transactionPoints sums what a transaction earned, redeemed and had
+// reversed so far.
+func transactionPoints(tx *sql.Tx, transactionID string) (earned, redeemed, reversed int, err error) {
+	err = tx.QueryRow(`
+    SELECT COALESCE(SUM(points) FILTER (WHERE type = 'earn'), 0),
+        COALESCE(-SUM(points) FILTER (WHERE type = 'redeem'), 0),
+        COALESCE(-SUM(points) FILTER (WHERE type = 'reverse'), 0)
+    FROM points_ledger WHERE transactionId = $1`, transactionID).Scan(&earned, &redeemed, &reversed)
+	return earned, redeemed, reversed, err
+}
+
+// refundRedeemedPoints gives back the points a refund pays back through
+// the points tender, rounded up to whole points like the redemption was.
+// Refunds of a sale never give back more points than it redeemed.
+func refundRedeemedPoints(tx *sql.Tx, transactionID string, refundID int, customerID string, baseAmount helper.Money) error {
+	var redeemed, restored int
+	err := tx.QueryRow(`
+    SELECT COALESCE(-SUM(points) FILTER (WHERE type = 'redeem'), 0),
+        COALESCE(SUM(points) FILTER (WHERE type = 'restore'), 0)
+    FROM points_ledger WHERE transactionId = $1`, transactionID).Scan(&redeemed, &restored)
+	if err != nil {
+		return err
+	}
+	value := pointsRedeemValue()
+	points := int((baseAmount + value - 1) / value)
+	if points > redeemed-restored {
+		points = redeemed - restored
+	}
+	return creditPoints(tx, customerID, transactionID, refundID, "restore", points)
+}
+
+// reverseRefundPoints takes back the points earned on the refunded part of
+// a sale. Points the customer has already spent cannot be taken back; a
+// later refund of the same sale tries again for what is still owed.
+func reverseRefundPoints(tx *sql.Tx, transactionID string, refundID int, customerID string, fullyRefunded bool) error {
+	earned, _, reversed, err := transactionPoints(tx, transactionID)
+	if err != nil || earned == 0 {
+		return err
+	}
+	owed := earned
+	if !fullyRefunded {
+		var refunded, total helper.Money
+		err := tx.QueryRow(`
+        SELECT (SELECT COALESCE(SUM(amount), 0) FROM refunds WHERE transactionId = $1), total
+        FROM transactions WHERE id = $1`, transactionID).Scan(&refunded, &total)
+		if err != nil {
+			return err
+		}
+		owed = int(int64(earned) * int64(refunded) / int64(total))
+	}
+	_, err = debitPoints(tx, customerID, transactionID, refundID, "reverse", owed-reversed)
+	return err
+}
+
+// reverseVoidPoints undoes the points side of a voided sale: earned points
+// are taken back and redeemed points are given back as a new credit.
+func reverseVoidPoints(tx *sql.Tx, transactionID string, customerID string) error {
+	earned, redeemed, reversed, err := transactionPoints(tx, transactionID)
+	if err != nil {
+		return err
+	}
+	if _, err := debitPoints(tx, customerID, transactionID, nil, "reverse", earned-reversed); err != nil {
+		return err
+	}
+	return creditPoints(tx, customerID, transactionID, nil, "restore", redeemed)
+}
+
+func GetCustomerPoints(c *gin.Context) {
+	var params model.GetPointsParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	customerID := c.Param("id")
+	if _, err := strconv.Atoi(customerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	exists, err := customerExists(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check customer"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	balance, err := pointsBalance(config.DB, customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Points"})
+		return
+	}
+	rows, err := config.DB.Query(`
+    SELECT id, type, points, COALESCE(transactionId::text, ''), COALESCE(refundId::text, ''), expiresAt, createdAt
+    FROM points_ledger
+    WHERE customerId = $1
+    ORDER BY createdAt DESC, id DESC
+    LIMIT $2 OFFSET $3`, customerID, params.Limit, params.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Points"})
+		return
+	}
+	defer rows.Close()
+	history := []model.PointsEntry{}
+	for rows.Next() {
+		var entry model.PointsEntry
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&entry.ID, &entry.Type, &entry.Points, &entry.TransactionID, &entry.RefundID, &expiresAt, &entry.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Points"})
+			return
+		}
+		if expiresAt.Valid {
+			t := expiresAt.Time
+			entry.ExpiresAt = &t
+		}
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Points"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data": model.PointsResponse{
+			CustomerID: customerID,
+			Balance:    balance,
+			History:    history,
+		},
+	})
+}
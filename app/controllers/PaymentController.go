@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"database/sql"
+	"math/big"
+	"net/http"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// settlePayments checks the tenders of a sale against its total and returns
+// them with the amount paid and the change due. Without payments the
+// request's paid amount is taken as a single cash tender, as before split
+// payments existed. Only cash may exceed what is due, so change always
+// comes out of cash.
+func settlePayments(request model.CheckoutRequest, total helper.Money) ([]model.CheckoutPayment, helper.Money, helper.Money, error) {
+	payments := request.Payments
+	if len(payments) == 0 {
+		if request.Paid == 0 || request.Change == nil {
+			return nil, 0, 0, &requestError{http.StatusBadRequest, "Either payments or paid and change are required"}
+		}
+		payments = []model.CheckoutPayment{{Method: "cash", Amount: request.Paid}}
+	}
+
+	var paid, nonCash helper.Money
+	for _, payment := range payments {
+		paid += payment.Amount
+		if payment.Method != "cash" {
+			nonCash += payment.Amount
+		}
+	}
+	if request.Paid != 0 && request.Paid != paid {
+		return nil, 0, 0, &requestError{http.StatusBadRequest, "Paid does not match payments"}
+	}
+	if paid < total {
+		return nil, 0, 0, &requestError{http.StatusBadRequest, "Paid is not enough"}
+	}
+	if nonCash > total {
+		return nil, 0, 0, &requestError{http.StatusBadRequest, "Only cash payments can exceed the total"}
+	}
+	change := paid - total
+	if request.Change != nil && *request.Change != change {
+		return nil, 0, 0, &requestError{http.StatusBadRequest, "Change is not correct"}
+	}
+	return payments, paid, change, nil
+}
+
+// redeemPayment takes a tender's value from the balance it is drawn on.
+// Cash, card and e-wallet payments are settled outside the system.
+func redeemPayment(tx *sql.Tx, transactionID int, customerID string, payment model.CheckoutPayment, baseAmount helper.Money) error {
+	switch payment.Method {
+	case "points":
+		return redeemPoints(tx, transactionID, customerID, baseAmount)
+	case "store_credit":
+		return redeemStoreCredit(tx, transactionID, customerID, baseAmount)
+	case "gift_card":
+		if payment.Reference == "" {
+			return &requestError{http.StatusBadRequest, "Gift card code is required"}
+		}
+		return redeemGiftCard(tx, transactionID, payment.Reference, baseAmount)
+	}
+	return nil
+}
+
+// recordPayments redeems and stores the tenders of a transaction.
+func recordPayments(tx *sql.Tx, transactionID int, customerID string, payments []model.CheckoutPayment, rate *big.Rat) ([]model.TransactionPayment, error) {
+	recorded := make([]model.TransactionPayment, 0, len(payments))
+	for _, payment := range payments {
+		baseAmount := toBase(payment.Amount, rate)
+		if err := redeemPayment(tx, transactionID, customerID, payment, baseAmount); err != nil {
+			return nil, err
+		}
+		var reference interface{}
+		if payment.Reference != "" {
+			reference = payment.Reference
+		}
+		_, err := tx.Exec("INSERT INTO transaction_payments (transactionId, method, amount, baseAmount, reference) VALUES ($1, $2, $3, $4, $5)",
+			transactionID, payment.Method, payment.Amount, baseAmount, reference)
+		if err != nil {
+			return nil, err
+		}
+		recorded = append(recorded, model.TransactionPayment{
+			Method:     payment.Method,
+			Amount:     payment.Amount,
+			BaseAmount: baseAmount,
+			Reference:  paymentReference(payment.Method, payment.Reference),
+		})
+	}
+	return recorded, nil
+}
+
+// getTransactionPayments loads the tenders of the given transactions, with
+// gift card codes masked.
+func getTransactionPayments(transactionIDs []string) (map[string][]model.TransactionPayment, error) {
+	rows, err := config.DB.Query(`
+    SELECT transactionId, method, amount, baseAmount, COALESCE(reference, '')
+    FROM transaction_payments
+    WHERE transactionId = ANY($1::int[])
+    ORDER BY id ASC`, pq.Array(transactionIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	payments := map[string][]model.TransactionPayment{}
+	for rows.Next() {
+		var transactionID string
+		var p model.TransactionPayment
+		if err := rows.Scan(&transactionID, &p.Method, &p.Amount, &p.BaseAmount, &p.Reference); err != nil {
+			return nil, err
+		}
+		p.Reference = paymentReference(p.Method, p.Reference)
+		payments[transactionID] = append(payments[transactionID], p)
+	}
+	return payments, rows.Err()
+}
+
+func GetTransactionPayments(c *gin.Context) {
+	transactionID := c.Param("id")
+	if _, err := strconv.Atoi(transactionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM transactions WHERE id = $1)", transactionID).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check transaction existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	payments, err := getTransactionPayments([]string{transactionID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Payments"})
+		return
+	}
+	result := payments[transactionID]
+	if result == nil {
+		result = []model.TransactionPayment{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    result,
+	})
+}
@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+func TestSettlePaymentsChange(t *testing.T) {
+	methods := []string{"cash", "card", "ewallet", "store_credit", "gift_card", "points"}
+	f := func(seed int64, total uint32) bool {
+		r := rand.New(rand.NewSource(seed))
+		var payments []model.CheckoutPayment
+		var paid helper.Money
+		for i := r.Intn(4); i >= 0; i-- {
+			payment := model.CheckoutPayment{Method: methods[r.Intn(len(methods))], Amount: helper.Money(r.Int63n(10_000_000) + 1)}
+			payments = append(payments, payment)
+			paid += payment.Amount
+		}
+		settled, gotPaid, change, err := settlePayments(model.CheckoutRequest{Payments: payments}, helper.Money(total))
+		if err != nil {
+			// Only rejected when underpaid or non-cash tenders exceed the total
+			return paid < helper.Money(total) || nonCashTotal(payments) > helper.Money(total)
+		}
+		return len(settled) == len(payments) && gotPaid == paid && change == paid-helper.Money(total) && change >= 0
+	}
+	if err := quick.Check(f, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSettlePaymentsPaidAndChange(t *testing.T) {
+	change := func(m helper.Money) *helper.Money { return &m }
+	tests := []struct {
+		name    string
+		request model.CheckoutRequest
+		total   helper.Money
+		paid    helper.Money
+		change  helper.Money
+		wantErr bool
+	}{
+		{"exact cash", model.CheckoutRequest{Paid: 5000, Change: change(0)}, 5000, 5000, 0, false},
+		{"cash with change", model.CheckoutRequest{Paid: 10000, Change: change(2550)}, 7450, 10000, 2550, false},
+		{"wrong change", model.CheckoutRequest{Paid: 10000, Change: change(2500)}, 7450, 0, 0, true},
+		{"missing change", model.CheckoutRequest{Paid: 10000}, 7450, 0, 0, true},
+		{"not enough", model.CheckoutRequest{Paid: 5000, Change: change(0)}, 7450, 0, 0, true},
+		{"card over total", model.CheckoutRequest{Payments: []model.CheckoutPayment{{Method: "card", Amount: 8000}}}, 7450, 0, 0, true},
+		{"split with cash change", model.CheckoutRequest{Payments: []model.CheckoutPayment{
+			{Method: "card", Amount: 5000},
+			{Method: "cash", Amount: 5000},
+		}, Change: change(2550)}, 7450, 10000, 2550, false},
+		{"paid disagrees with payments", model.CheckoutRequest{Paid: 9000, Payments: []model.CheckoutPayment{{Method: "cash", Amount: 10000}}}, 7450, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, paid, change, err := settlePayments(tt.request, tt.total)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (paid != tt.paid || change != tt.change) {
+				t.Errorf("paid, change = %v, %v; want %v, %v", paid, change, tt.paid, tt.change)
+			}
+		})
+	}
+}
+
+func nonCashTotal(payments []model.CheckoutPayment) helper.Money {
+	var total helper.Money
+	for _, payment := range payments {
+		if payment.Method != "cash" {
+			total += payment.Amount
+		}
+	}
+	return total
+}
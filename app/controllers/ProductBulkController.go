@@ -0,0 +1,250 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"sort"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// resolveBulkTargets returns the sorted product ids a bulk request acts on.
+// Exactly one of ids or filter must be given, and a filter must narrow the
+// catalog down by at least one field.
+func resolveBulkTargets(ids []string, filter *model.ProductBulkFilter) ([]string, error) {
+	if (len(ids) > 0) == (filter != nil) {
+		return nil, &requestError{http.StatusBadRequest, "Either ids or filter is required"}
+	}
+
+	if filter == nil {
+		seen := map[string]bool{}
+		var targets []string
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				targets = append(targets, id)
+			}
+		}
+		sortIDs(targets)
+		return targets, nil
+	}
+
+	if *filter == (model.ProductBulkFilter{}) {
+		return nil, &requestError{http.StatusBadRequest, "Filter must not be empty"}
+	}
+	where, args := productFilters(model.GetProductParams{
+		Name:        filter.Name,
+		IsAvailable: filter.IsAvailable,
+		Category:    filter.Category,
+		CategoryID:  filter.CategoryID,
+		SKU:         filter.SKU,
+		InStock:     filter.InStock,
+	})
+	rows, err := config.DB.Query("SELECT p.id FROM "+productSource+" p"+where+" ORDER BY p.id ASC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	targets := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		targets = append(targets, id)
+	}
+	return targets, rows.Err()
+}
+
+// sortIDs orders numeric ids so rows are always locked in the same order.
+func sortIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+}
+
+// bulkUpdateProduct applies the update to one locked product and reports
+// the outcome for it. A price change starts from the price in effect and,
+// like a single product update, ends the scheduled prices in effect so the
+// new price shows.
+func bulkUpdateProduct(tx *sql.Tx, id string, update model.ProductBulkUpdate, actorID int) (model.ProductBulkOutcome, error) {
+	outcome := model.ProductBulkOutcome{ID: id}
+
+	var productType string
+	var bundlePricing sql.NullString
+	var price helper.Money
+	err := tx.QueryRow("SELECT p.type, p.bundlePricing, "+currentPrice("p")+" FROM products p WHERE p.id = $1 AND p.deletedAt IS NULL FOR UPDATE", id).Scan(&productType, &bundlePricing, &price)
+	if err == sql.ErrNoRows {
+		outcome.Status = "not_found"
+		return outcome, nil
+	}
+	if err != nil {
+		return outcome, err
+	}
+
+	changesPrice := update.Price != nil || update.PriceChangePercent != nil
+	if changesPrice && productType == "bundle" && bundlePricing.String == "computed" {
+		outcome.Status = "skipped"
+		outcome.Reason = "Price of a computed bundle follows its components"
+		return outcome, nil
+	}
+	if update.Price != nil {
+		price = *update.Price
+	}
+	if update.PriceChangePercent != nil {
+		price = price.Percent(100+*update.PriceChangePercent, "half_up")
+		if price < 100 {
+			outcome.Status = "skipped"
+			outcome.Reason = "Resulting price is below the minimum"
+			return outcome, nil
+		}
+	}
+
+	query := "UPDATE products SET version = version + 1, updatedAt = NOW()"
+	args := []interface{}{}
+	if changesPrice {
+		args = append(args, price)
+		query += ", price = $" + strconv.Itoa(len(args))
+	}
+	if update.IsAvailable != nil {
+		args = append(args, *update.IsAvailable)
+		query += ", isAvailable = $" + strconv.Itoa(len(args))
+	}
+	if update.CategoryID != nil {
+		args = append(args, *update.CategoryID)
+		query += ", categoryId = $" + strconv.Itoa(len(args))
+	}
+	args = append(args, id)
+	query += " WHERE id = $" + strconv.Itoa(len(args))
+	err = withProductAudit(tx, id, "update", actorID, func() error {
+		if changesPrice {
+			if err := endCurrentPrices(tx, id); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(query, args...)
+		return err
+	})
+	if err != nil {
+		return outcome, err
+	}
+	outcome.Status = "updated"
+	return outcome, nil
+}
+
+func BulkUpdateProduct(c *gin.Context) {
+	var request model.ProductBulkUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	update := request.Update
+	if update == (model.ProductBulkUpdate{}) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing to update"})
+		return
+	}
+	if update.Price != nil && update.PriceChangePercent != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Use either price or priceChangePercent"})
+		return
+	}
+	if update.CategoryID != nil {
+		isActive, err := categoryIsActive(*update.CategoryID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category"})
+			return
+		}
+		if !isActive {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+			return
+		}
+	}
+
+	targets, err := resolveBulkTargets(request.IDs, request.Filter)
+	if err != nil {
+		respondRequestError(c, err, "Error when Update Product")
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Product"})
+		return
+	}
+	defer tx.Rollback()
+
+	outcomes := []model.ProductBulkOutcome{}
+	for _, id := range targets {
+		outcome, err := bulkUpdateProduct(tx, id, update, c.GetInt("userId"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Product"})
+			return
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Product"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    outcomes,
+	})
+}
+
+func BulkDeleteProduct(c *gin.Context) {
+	var request model.ProductBulkDeleteRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	targets, err := resolveBulkTargets(request.IDs, request.Filter)
+	if err != nil {
+		respondRequestError(c, err, "Error when Delete Product")
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Product"})
+		return
+	}
+	defer tx.Rollback()
+
+	outcomes := []model.ProductBulkOutcome{}
+	for _, id := range targets {
+		var exists bool
+		err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deletedAt IS NULL)", id).Scan(&exists)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Product"})
+			return
+		}
+		if !exists {
+			outcomes = append(outcomes, model.ProductBulkOutcome{ID: id, Status: "not_found"})
+			continue
+		}
+		err = withProductAudit(tx, id, "delete", c.GetInt("userId"), func() error {
+			_, err := tx.Exec("UPDATE products SET deletedAt = NOW(), version = version + 1 WHERE id = $1 AND deletedAt IS NULL", id)
+			return err
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Product"})
+			return
+		}
+		outcomes = append(outcomes, model.ProductBulkOutcome{ID: id, Status: "deleted"})
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Product"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    outcomes,
+	})
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"database/sql"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,6 +13,225 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// currentPrice resolves the price in effect now for the products row aliased
+// as alias: the latest scheduled price whose period covers NOW(), falling
+// back to the product's base price. Inside a transaction NOW() is the
+// transaction start, so a sale sees a single consistent price.
+func currentPrice(alias string) string {
+	return `COALESCE((
+                SELECT pp.price FROM product_prices pp
+                WHERE pp.productId = ` + alias + `.id
+                    AND pp.effectiveFrom <= NOW()
+                    AND (pp.effectiveTo IS NULL OR pp.effectiveTo > NOW())
+                ORDER BY pp.effectiveFrom DESC, pp.id DESC
+                LIMIT 1
+            ), ` + alias + `.price)`
+}
+
+// productSource exposes products with the values shown to clients: prices
+// are resolved from the price schedule, bundles derive their stock from
+// their components and, when priced as "computed", their price from
+// component prices minus the bundle discount. Available is the stock not
+// reserved by carts.
+var productSource = `(
+    SELECT
+        pr.id, pr.name, pr.sku, pr.categoryId, pr.taxClassId, pr.imageUrl, pr.notes,
+        CASE WHEN pr.type = 'bundle' AND pr.bundlePricing = 'computed'
+            THEN ROUND(COALESCE((
+                SELECT SUM(` + currentPrice("cp") + ` * bi.quantity)
+                FROM bundle_items bi JOIN products cp ON cp.id = bi.productId
+                WHERE bi.bundleId = pr.id
+            ), 0) * (100 - pr.bundleDiscount) / 100, 2)
+            ELSE ` + currentPrice("pr") + ` END AS price,
+        CASE WHEN pr.type = 'bundle'
+            THEN COALESCE((
+                SELECT MIN(CASE WHEN cp.deletedAt IS NULL AND cp.isAvailable THEN cp.stock / bi.quantity ELSE 0 END)
+                FROM bundle_items bi JOIN products cp ON cp.id = bi.productId
+                WHERE bi.bundleId = pr.id
+            ), 0)
+            ELSE pr.stock END AS stock,
+        CASE WHEN pr.type = 'bundle'
+            THEN COALESCE((
+                SELECT MIN(CASE WHEN cp.deletedAt IS NULL AND cp.isAvailable THEN GREATEST(cp.stock - ` + reservedStock("productId", "cp.id") + `, 0) / bi.quantity ELSE 0 END)
+                FROM bundle_items bi JOIN products cp ON cp.id = bi.productId
+                WHERE bi.bundleId = pr.id
+            ), 0)
+            ELSE GREATEST(pr.stock - ` + reservedStock("productId", "pr.id") + `, 0) END AS available,
+        pr.location, pr.isAvailable, pr.type, pr.version, pr.createdAt, pr.deletedAt
+    FROM products pr
+)`
+
+const productColumns = "p.id, p.name, p.sku, COALESCE(p.categoryId::text, ''), COALESCE(c.name, ''), p.imageUrl, p.notes, p.price, p.stock, p.location, p.isAvailable, p.type, p.createdAt, COALESCE(p.taxClassId::text, ''), p.available, p.version"
+
+const productJoins = " LEFT JOIN categories c ON c.id = p.categoryId"
+
+// productSelect builds the SELECT ... FROM part of a product listing for the
+// requested variants mode.
+func productSelect(variants string) string {
+	if variants == "flat" {
+		return "SELECT " + productColumns + ", p.variantId, p.variantOptions FROM " + flatProductSource + " p" + productJoins
+	}
+	return "SELECT " + productColumns + " FROM " + productSource + " p" + productJoins
+}
+
+func scanProduct(rows *sql.Rows, extra ...interface{}) (model.ProductResponse, error) {
+	var p model.ProductResponse
+	dest := []interface{}{&p.ID, &p.Name, &p.SKU, &p.CategoryID, &p.Category, &p.ImageURL, &p.Notes, &p.Price, &p.Stock, &p.Location, &p.IsAvailable, &p.Type, &p.CreatedAt, &p.TaxClassID, &p.Available, &p.Version}
+	err := rows.Scan(append(dest, extra...)...)
+	return p, err
+}
+
+// scanProductRows reads a product listing, loads the components of bundles
+// and, for variants=nested, the options and variants of every product.
+func scanProductRows(rows *sql.Rows, variants string) ([]model.ProductResponse, error) {
+	defer rows.Close()
+	var products []model.ProductResponse
+	for rows.Next() {
+		var p model.ProductResponse
+		var err error
+		if variants == "flat" {
+			p, err = scanFlatVariant(rows)
+		} else {
+			p, err = scanProduct(rows)
+		}
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := attachBundleItems(products); err != nil {
+		return nil, err
+	}
+	if variants == "nested" {
+		if err := attachVariants(products); err != nil {
+			return nil, err
+		}
+	}
+	return products, nil
+}
+
+// dbExecutor is satisfied by both *sql.DB and *sql.Tx, so product writes can
+// run on their own or as part of a larger transaction.
+type dbExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func insertProduct(db dbExecutor, product model.ProductRequest) (int, error) {
+	var lastInsertedID int
+	err := db.QueryRow("INSERT INTO products (name, sku, categoryId, imageUrl, notes, price, stock, location, isAvailable) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+		product.Name, product.SKU, product.CategoryID, product.ImageURL, product.Notes, product.Price, *product.Stock, product.Location, *product.IsAvailable).Scan(&lastInsertedID)
+	return lastInsertedID, err
+}
+
+// lockSKU holds a lock on sku until tx ends, so writers that check whether a
+// SKU is free and then claim it cannot interleave. A row lock cannot do this
+// while no live product has the SKU yet.
+func lockSKU(tx *sql.Tx, sku string) error {
+	_, err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", sku)
+	return err
+}
+
+// endCurrentPrices ends the scheduled prices in effect for a product, so a
+// newly written base price applies from now on. Prices scheduled for later
+// are kept.
+func endCurrentPrices(db dbExecutor, productID string) error {
+	_, err := db.Exec(`
+    UPDATE product_prices SET effectiveTo = NOW()
+    WHERE productId = $1 AND effectiveFrom <= NOW() AND (effectiveTo IS NULL OR effectiveTo > NOW())`, productID)
+	return err
+}
+
+// updateProduct writes a product. Price is the price to charge from now
+// on: when it differs from the price in effect, the scheduled prices in
+// effect are ended so the new base price shows. Sending back the price in
+// effect leaves the base price and the schedule alone.
+func updateProduct(db dbExecutor, productID string, product model.ProductRequest) error {
+	var basePrice, price helper.Money
+	err := db.QueryRow("SELECT p.price, "+currentPrice("p")+" FROM products p WHERE p.id = $1", productID).Scan(&basePrice, &price)
+	if err != nil {
+		return err
+	}
+	if product.Price == price {
+		product.Price = basePrice
+	} else if err := endCurrentPrices(db, productID); err != nil {
+		return err
+	}
+
+	query := `
+    UPDATE products 
+    SET 
+        name = $1,
+        sku = $2,
+        categoryId = $3,
+        imageUrl = $4,
+        notes = $5,
+        price = $6,
+        stock = $7,
+        location = $8,
+        isAvailable = $9,
+        version = version + 1,
+        updatedAt = NOW()
+    WHERE 
+        id = $10
+        AND deletedAt IS NULL
+`
+	_, err = db.Exec(query, product.Name, product.SKU, product.CategoryID, product.ImageURL, product.Notes, product.Price, *product.Stock, product.Location, *product.IsAvailable, productID)
+	return err
+}
+
+// responseETag is the ETag of a product as shown to clients. The version
+// follows writes to the product row; the price, stock and available shown
+// also follow the price schedule, bundle components and cart reservations,
+// so they are part of the tag.
+func responseETag(p model.ProductResponse) string {
+	return helper.DerivedETag(p.Version, p.Price, p.Stock, p.Available)
+}
+
+// productETag loads the ETag of a product as it is now.
+func productETag(db dbExecutor, productID string) (string, error) {
+	var p model.ProductResponse
+	err := db.QueryRow("SELECT p.version, p.price, p.stock, p.available FROM "+productSource+" p WHERE p.id = $1", productID).Scan(&p.Version, &p.Price, &p.Stock, &p.Available)
+	return responseETag(p), err
+}
+
+// lockProductVersion locks a product for a write and checks the request's
+// If-Match header against the product's ETag. The header may be left out
+// only when PRODUCT_IF_MATCH is set to "optional".
+func lockProductVersion(c *gin.Context, tx *sql.Tx, productID string) error {
+	if _, err := strconv.Atoi(productID); err != nil {
+		return &requestError{http.StatusNotFound, "Product not found"}
+	}
+	var id int
+	err := tx.QueryRow("SELECT id FROM products WHERE id = $1 AND deletedAt IS NULL FOR UPDATE", productID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return &requestError{http.StatusNotFound, "Product not found"}
+	}
+	if err != nil {
+		return err
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		if config.GetEnv("PRODUCT_IF_MATCH", "required") != "optional" {
+			return &requestError{http.StatusPreconditionRequired, "If-Match header is required"}
+		}
+		return nil
+	}
+	etag, err := productETag(tx, productID)
+	if err != nil {
+		return err
+	}
+	if !helper.ETagMatches(ifMatch, etag, false) {
+		return &requestError{http.StatusPreconditionFailed, "Product has been modified"}
+	}
+	return nil
+}
+
 func AddProduct(c *gin.Context) {
 	var product model.ProductRequest
 	if err := c.ShouldBindJSON(&product); err != nil {
@@ -24,74 +244,116 @@ func AddProduct(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
-	_, err := config.DB.Query("INSERT INTO products (name, sku, category, imageUrl, notes, price, stock, location, isAvailable)VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)", product.Name, product.SKU, product.Category, product.ImageURL, product.Notes, product.Price, product.Stock, product.Location, product.IsAvailable)
+
+	isActive, err := categoryIsActive(product.CategoryID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category"})
+		return
+	}
+	if !isActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
+		return
+	}
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
+		return
+	}
+	defer tx.Rollback()
+
+	productID, err := insertProduct(tx, product)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
 		return
 	}
+	if err := auditProductCreate(tx, strconv.Itoa(productID), c.GetInt("userId")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
+		return
+	}
 	c.JSON(http.StatusCreated, gin.H{"message": "Product added successfully"})
 
 }
 
-func GetAllProduct(c *gin.Context) {
-	var products []model.ProductResponse
-	query := "SELECT id, name, sku, category, imageUrl, notes, price, stock, location, isAvailable, createdAt FROM products WHERE 1=1 AND deletedAt IS NULL"
+// productFilters builds the WHERE clause of GetAllProduct from its query
+// parameters. It is shared with the catalog export so both always select
+// the same products.
+func productFilters(params model.GetProductParams) (string, []interface{}) {
+	query := " WHERE 1=1 AND p.deletedAt IS NULL"
 	args := []interface{}{}
 
-	var params model.GetProductParams
-
-	if err := c.BindQuery(&params); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
-		return
-	}
-
 	if params.ID != "" {
-		query += " AND id = $" + strconv.Itoa(len(args)+1)
+		query += " AND p.id = $" + strconv.Itoa(len(args)+1)
 		args = append(args, params.ID)
 	}
 	if params.Name != "" {
-		query += " AND lower(name) LIKE $" + strconv.Itoa(len(args)+1)
+		query += " AND lower(p.name) LIKE $" + strconv.Itoa(len(args)+1)
 		args = append(args, "%"+strings.ToLower(params.Name)+"%")
 	}
 	if params.IsAvailable == "true" || params.IsAvailable == "1" {
-		query += " AND isAvailable = $" + strconv.Itoa(len(args)+1)
+		query += " AND p.isAvailable = $" + strconv.Itoa(len(args)+1)
 		args = append(args, params.IsAvailable)
 	} else if params.IsAvailable == "false" || params.IsAvailable == "0" {
-		query += " AND isAvailable = $" + strconv.Itoa(len(args)+1)
+		query += " AND p.isAvailable = $" + strconv.Itoa(len(args)+1)
 		args = append(args, params.IsAvailable)
 	}
 
 	if params.Category != "" {
-		switch params.Category {
-		case "Clothing", "Accessories", "Footwear", "Beverages":
-			query += " AND category = $" + strconv.Itoa(len(args)+1)
-			args = append(args, params.Category)
-		default:
-
-		}
+		query += categoryTreeFilter("lower(name) = lower($" + strconv.Itoa(len(args)+1) + ")")
+		args = append(args, params.Category)
+	}
+	if params.CategoryID != "" {
+		query += categoryTreeFilter("id = $" + strconv.Itoa(len(args)+1))
+		args = append(args, params.CategoryID)
 	}
 	if params.SKU != "" {
-		query += " AND sku =$" + strconv.Itoa(len(args)+1)
+		query += " AND p.sku =$" + strconv.Itoa(len(args)+1)
 		args = append(args, params.SKU)
 	}
 	if params.InStock != "" {
 		if params.InStock == "true" || params.InStock == "1" {
-			query += " AND stock > 0"
+			query += " AND p.available > 0"
 		}
 		if params.InStock == "false" || params.InStock == "0" {
-			query += " AND stock = 0"
+			query += " AND p.available = 0"
 		}
 	}
+	return query, args
+}
+
+// productOrder builds the ORDER BY clause for the price and createdAt sort
+// parameters.
+func productOrder(params model.GetProductParams) string {
+	var order []string
 	if params.PriceSort == "asc" {
-		query += " ORDER BY price ASC"
+		order = append(order, "p.price ASC")
 	} else if params.PriceSort == "desc" {
-		query += " ORDER BY price DESC"
+		order = append(order, "p.price DESC")
 	}
 	if params.CreatedAt == "asc" {
-		query += " ORDER BY createdAt ASC"
+		order = append(order, "p.createdAt ASC")
 	} else if params.CreatedAt == "desc" {
-		query += " ORDER BY createdAt DESC"
+		order = append(order, "p.createdAt DESC")
+	}
+	if len(order) == 0 {
+		return ""
 	}
+	return " ORDER BY " + strings.Join(order, ", ")
+}
+
+func GetAllProduct(c *gin.Context) {
+	var params model.GetProductParams
+
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	where, args := productFilters(params)
+	query := productSelect(params.Variants) + where + productOrder(params)
 	query += " LIMIT $" + strconv.Itoa(len(args)+1)
 	args = append(args, params.Limit)
 	query += " OFFSET $" + strconv.Itoa(len(args)+1)
@@ -103,15 +365,10 @@ func GetAllProduct(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve 1"})
 		return
 	}
-	// defer rows.Close()
-	for rows.Next() {
-		var p model.ProductResponse
-		err := rows.Scan(&p.ID, &p.Name, &p.SKU, &p.Category, &p.ImageURL, &p.Notes, &p.Price, &p.Stock, &p.Location, &p.IsAvailable, &p.CreatedAt)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve"})
-			return
-		}
-		products = append(products, p)
+	products, err := scanProductRows(rows, params.Variants)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve"})
+		return
 	}
 	if len(products) == 0 {
 		// Return response with empty array
@@ -127,6 +384,44 @@ func GetAllProduct(c *gin.Context) {
 	})
 }
 
+// GetProduct returns a single product with its ETag. A request whose
+// If-None-Match still matches gets 304 without a body, so clients can poll
+// a product cheaply. The ETag changes whenever the product shown does,
+// including price, stock and available derived from other rows.
+func GetProduct(c *gin.Context) {
+	productID := c.Param("id")
+	if _, err := strconv.Atoi(productID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	rows, err := config.DB.Query(productSelect("")+" WHERE p.id = $1 AND p.deletedAt IS NULL", productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Product"})
+		return
+	}
+	products, err := scanProductRows(rows, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Product"})
+		return
+	}
+	if len(products) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	etag := responseETag(products[0])
+	c.Header("ETag", etag)
+	if ifNoneMatch := c.GetHeader("If-None-Match"); ifNoneMatch != "" && helper.ETagMatches(ifNoneMatch, etag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    products[0],
+	})
+}
+
 func UpdateProduct(c *gin.Context) {
 	var product model.ProductRequest
 	if err := c.ShouldBindJSON(&product); err != nil {
@@ -141,77 +436,87 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	var exists bool
-	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deletedAt IS NULL)", productID).Scan(&exists)
+	isActive, err := categoryIsActive(product.CategoryID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category"})
 		return
 	}
-	if !exists {
-		// Product does not exist
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	if !isActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Category not found"})
 		return
 	}
-	query := `
-    UPDATE products 
-    SET 
-        name = $1,
-        sku = $2,
-        category = $3,
-        imageUrl = $4,
-        notes = $5,
-        price = $6,
-        stock = $7,
-        location = $8,
-        isAvailable = $9
-    WHERE 
-        id = $10
-        AND deletedAt IS NULL
-`
-	_, err = config.DB.Query(query, product.Name, product.SKU, product.Category, product.ImageURL, product.Notes, product.Price, product.Stock, product.Location, product.IsAvailable, productID)
+
+	tx, err := config.DB.Begin()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Product"})
-}
+	defer tx.Rollback()
 
-func DeleteProduct(c *gin.Context) {
-	productID := c.Param("id")
-	var exists bool
-	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deletedAt IS NULL)", productID).Scan(&exists)
+	if err := lockProductVersion(c, tx, productID); err != nil {
+		respondRequestError(c, err, "Error when Add Product")
+		return
+	}
+	err = withProductAudit(tx, productID, "update", c.GetInt("userId"), func() error {
+		return updateProduct(tx, productID, product)
+	})
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product Not Found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
 		return
 	}
-	if !exists {
-		// Product does not exist
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	etag, err := productETag(tx, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
 		return
 	}
+	c.Header("ETag", etag)
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Product"})
+}
+
+func DeleteProduct(c *gin.Context) {
+	productID := c.Param("id")
 	query := `
     UPDATE products 
     SET 
-        deletedAt = NOW()
+        deletedAt = NOW(),
+        version = version + 1
     WHERE 
         id = $1
         AND deletedAt IS NULL
 `
-	_, err = config.DB.Query(query, productID)
+	tx, err := config.DB.Begin()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
 		return
 	}
+	defer tx.Rollback()
+
+	if err := lockProductVersion(c, tx, productID); err != nil {
+		respondRequestError(c, err, "Error when Add Product")
+		return
+	}
+	err = withProductAudit(tx, productID, "delete", c.GetInt("userId"), func() error {
+		_, err := tx.Exec(query, productID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Product"})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully Delete Product"})
 }
 
 // Search SKU
 
 func GetSKUProduct(c *gin.Context) {
-	var products []model.ProductResponse
-	query := "SELECT id, name, sku, category, imageUrl, notes, price, stock, location, isAvailable, createdAt FROM products WHERE 1=1 AND isAvailable = true AND deletedAt IS NULL"
-	args := []interface{}{}
-
 	var params model.GetProductParams
 
 	if err := c.BindQuery(&params); err != nil {
@@ -219,36 +524,38 @@ func GetSKUProduct(c *gin.Context) {
 		return
 	}
 
+	query := productSelect(params.Variants) + " WHERE 1=1 AND p.isAvailable = true AND p.deletedAt IS NULL"
+	args := []interface{}{}
+
 	if params.Name != "" {
-		query += " AND lower(name) LIKE $" + strconv.Itoa(len(args)+1)
+		query += " AND lower(p.name) LIKE $" + strconv.Itoa(len(args)+1)
 		args = append(args, "%"+strings.ToLower(params.Name)+"%")
 	}
 
 	if params.Category != "" {
-		switch params.Category {
-		case "Clothing", "Accessories", "Footwear", "Beverages":
-			query += " AND category = $" + strconv.Itoa(len(args)+1)
-			args = append(args, params.Category)
-		default:
-
-		}
+		query += categoryTreeFilter("lower(name) = lower($" + strconv.Itoa(len(args)+1) + ")")
+		args = append(args, params.Category)
+	}
+	if params.CategoryID != "" {
+		query += categoryTreeFilter("id = $" + strconv.Itoa(len(args)+1))
+		args = append(args, params.CategoryID)
 	}
 	if params.SKU != "" {
-		query += " AND sku =$" + strconv.Itoa(len(args)+1)
+		query += " AND p.sku =$" + strconv.Itoa(len(args)+1)
 		args = append(args, params.SKU)
 	}
 	if params.InStock != "" {
 		if params.InStock == "true" || params.InStock == "1" {
-			query += " AND stock > 0"
+			query += " AND p.available > 0"
 		}
 		if params.InStock == "false" || params.InStock == "0" {
-			query += " AND stock = 0"
+			query += " AND p.available = 0"
 		}
 	}
 	if params.PriceSort == "asc" {
-		query += " ORDER BY price ASC"
+		query += " ORDER BY p.price ASC"
 	} else if params.PriceSort == "desc" {
-		query += " ORDER BY price DESC"
+		query += " ORDER BY p.price DESC"
 	}
 
 	query += " LIMIT $" + strconv.Itoa(len(args)+1)
@@ -262,15 +569,10 @@ func GetSKUProduct(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve 1"})
 		return
 	}
-	// defer rows.Close()
-	for rows.Next() {
-		var p model.ProductResponse
-		err := rows.Scan(&p.ID, &p.Name, &p.SKU, &p.Category, &p.ImageURL, &p.Notes, &p.Price, &p.Stock, &p.Location, &p.IsAvailable, &p.CreatedAt)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve"})
-			return
-		}
-		products = append(products, p)
+	products, err := scanProductRows(rows, params.Variants)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve"})
+		return
 	}
 	if len(products) == 0 {
 		// Return response with empty array
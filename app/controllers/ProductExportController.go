@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery is how many rows are written between flushes to the
+// client.
+const exportFlushEvery = 100
+
+// productExportColumns lists every exportable column in the order they are
+// always written, whatever order the client asked for them in.
+var productExportColumns = []string{"id", "variantId", "name", "sku", "categoryId", "category", "taxClassId", "imageUrl", "notes", "price", "stock", "location", "isAvailable", "type", "createdAt"}
+
+func productExportValue(p model.ProductResponse, column string) interface{} {
+	switch column {
+	case "id":
+		return p.ID
+	case "variantId":
+		return p.VariantID
+	case "name":
+		return p.Name
+	case "sku":
+		return p.SKU
+	case "categoryId":
+		return p.CategoryID
+	case "category":
+		return p.Category
+	case "taxClassId":
+		return p.TaxClassID
+	case "imageUrl":
+		return p.ImageURL
+	case "notes":
+		return p.Notes
+	case "price":
+		return p.Price
+	case "stock":
+		return p.Stock
+	case "location":
+		return p.Location
+	case "isAvailable":
+		return p.IsAvailable
+	case "type":
+		return p.Type
+	case "createdAt":
+		return p.CreatedAt
+	}
+	return nil
+}
+
+// selectExportColumns returns the requested columns in their stable order,
+// or every column except variantId when none are requested. It returns false
+// for unknown column names.
+func selectExportColumns(requested string, variants string) ([]string, bool) {
+	if requested == "" {
+		var columns []string
+		for _, column := range productExportColumns {
+			if column == "variantId" && variants != "flat" {
+				continue
+			}
+			columns = append(columns, column)
+		}
+		return columns, true
+	}
+
+	wanted := map[string]bool{}
+	for _, column := range strings.Split(requested, ",") {
+		wanted[strings.TrimSpace(column)] = true
+	}
+	var columns []string
+	for _, column := range productExportColumns {
+		if wanted[column] {
+			columns = append(columns, column)
+			delete(wanted, column)
+		}
+	}
+	return columns, len(wanted) == 0 && len(columns) > 0
+}
+
+func formatExportCSV(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case helper.Money:
+		return v.String()
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	encoded, _ := json.Marshal(value)
+	return strings.Trim(string(encoded), `"`)
+}
+
+// formatExportJSON writes the columns as a JSON object keeping their order,
+// which encoding/json does not do for maps.
+func formatExportJSON(p model.ProductResponse, columns []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, column := range columns {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(column)
+		value, _ := json.Marshal(productExportValue(p, column))
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+func ExportProduct(c *gin.Context) {
+	var params model.GetProductParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	var exportParams model.ProductExportParams
+	if err := c.ShouldBindQuery(&exportParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	if params.Variants != "flat" {
+		params.Variants = ""
+	}
+	columns, ok := selectExportColumns(exportParams.Columns, params.Variants)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid columns"})
+		return
+	}
+
+	// The whole filtered catalog is exported, so limit and offset are ignored
+	where, args := productFilters(params)
+	order := productOrder(params)
+	if order == "" {
+		order = " ORDER BY p.id ASC"
+	}
+	rows, err := config.DB.Query(productSelect(params.Variants)+where+order, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Export Product"})
+		return
+	}
+	defer rows.Close()
+
+	var csvWriter *csv.Writer
+	if exportParams.Format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="products.csv"`)
+		csvWriter = csv.NewWriter(c.Writer)
+		csvWriter.Write(columns)
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Header("Content-Disposition", `attachment; filename="products.jsonl"`)
+	}
+	c.Status(http.StatusOK)
+
+	// Rows are written as they are read so the catalog is never held in
+	// memory. Once streaming has started the status can no longer change,
+	// so errors only end the stream early.
+	count := 0
+	for rows.Next() {
+		var p model.ProductResponse
+		if params.Variants == "flat" {
+			p, err = scanFlatVariant(rows)
+		} else {
+			p, err = scanProduct(rows)
+		}
+		if err != nil {
+			log.Println("export product:", err)
+			break
+		}
+
+		if csvWriter != nil {
+			record := make([]string, len(columns))
+			for i, column := range columns {
+				record[i] = formatExportCSV(productExportValue(p, column))
+			}
+			csvWriter.Write(record)
+		} else {
+			c.Writer.Write(formatExportJSON(p, columns))
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			if csvWriter != nil {
+				csvWriter.Flush()
+			}
+			c.Writer.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("export product:", err)
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+	}
+	c.Writer.Flush()
+}
@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// productSnapshot holds the audited fields of a product row, keyed by their
+// JSON names.
+type productSnapshot map[string]interface{}
+
+func loadProductSnapshot(db dbExecutor, productID string) (productSnapshot, error) {
+	var name, productType string
+	var sku, categoryID, taxClassID, imageURL, notes, location, bundlePricing sql.NullString
+	var price helper.Money
+	var bundleDiscount float64
+	var stock int
+	var isAvailable bool
+	var deletedAt sql.NullTime
+	err := db.QueryRow(`
+    SELECT name, sku, categoryId::text, taxClassId::text, imageUrl, notes, price, stock, location, isAvailable, type, bundlePricing, bundleDiscount, deletedAt
+    FROM products WHERE id = $1`, productID).Scan(&name, &sku, &categoryID, &taxClassID, &imageURL, &notes, &price, &stock, &location, &isAvailable, &productType, &bundlePricing, &bundleDiscount, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	nullable := func(v sql.NullString) interface{} {
+		if v.Valid {
+			return v.String
+		}
+		return nil
+	}
+	snapshot := productSnapshot{
+		"name":           name,
+		"sku":            nullable(sku),
+		"categoryId":     nullable(categoryID),
+		"taxClassId":     nullable(taxClassID),
+		"imageUrl":       nullable(imageURL),
+		"notes":          nullable(notes),
+		"price":          price,
+		"stock":          stock,
+		"location":       nullable(location),
+		"isAvailable":    isAvailable,
+		"type":           productType,
+		"bundlePricing":  nullable(bundlePricing),
+		"bundleDiscount": bundleDiscount,
+		"deletedAt":      nil,
+	}
+	if deletedAt.Valid {
+		snapshot["deletedAt"] = deletedAt.Time.Format(time.RFC3339)
+	}
+	return snapshot, nil
+}
+
+// diffProductSnapshots returns the fields whose value differs. A nil before
+// snapshot (a newly created product) reports every field of after.
+func diffProductSnapshots(before, after productSnapshot) map[string]model.FieldChange {
+	changes := map[string]model.FieldChange{}
+	for field, newValue := range after {
+		var oldValue interface{}
+		if before != nil {
+			oldValue = before[field]
+		}
+		if before != nil && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		if before == nil && newValue == nil {
+			continue
+		}
+		changes[field] = model.FieldChange{Old: oldValue, New: newValue}
+	}
+	return changes
+}
+
+func recordProductAudit(db dbExecutor, productID string, action string, actorID int, before, after productSnapshot) error {
+	changes, err := json.Marshal(diffProductSnapshots(before, after))
+	if err != nil {
+		return err
+	}
+	var actor interface{}
+	if actorID != 0 {
+		actor = actorID
+	}
+	_, err = db.Exec("INSERT INTO product_audits (productId, action, actorId, changes) VALUES ($1, $2, $3, $4)",
+		productID, action, actor, string(changes))
+	return err
+}
+
+// withProductAudit runs change for an existing product inside tx and records
+// the resulting field-level diff as one audit entry.
+func withProductAudit(tx *sql.Tx, productID string, action string, actorID int, change func() error) error {
+	before, err := loadProductSnapshot(tx, productID)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	after, err := loadProductSnapshot(tx, productID)
+	if err != nil {
+		return err
+	}
+	return recordProductAudit(tx, productID, action, actorID, before, after)
+}
+
+// auditProductCreate records the creation of productID by actorID.
+func auditProductCreate(tx *sql.Tx, productID string, actorID int) error {
+	after, err := loadProductSnapshot(tx, productID)
+	if err != nil {
+		return err
+	}
+	return recordProductAudit(tx, productID, "create", actorID, nil, after)
+}
+
+func GetProductHistory(c *gin.Context) {
+	var params model.GetProductHistoryParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	productID := c.Param("id")
+	if _, err := strconv.Atoi(productID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	query := `
+    SELECT a.id, a.productId, a.action, COALESCE(a.actorId::text, ''), COALESCE(u.name, ''), a.changes, a.createdAt
+    FROM product_audits a
+    LEFT JOIN users u ON u.id = a.actorId
+    WHERE a.productId = $1
+    ORDER BY a.createdAt DESC, a.id DESC
+    LIMIT $2 OFFSET $3`
+	rows, err := config.DB.Query(query, productID, params.Limit, params.Offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve History"})
+		return
+	}
+	defer rows.Close()
+
+	history := []model.ProductAuditResponse{}
+	for rows.Next() {
+		var entry model.ProductAuditResponse
+		var changes []byte
+		if err := rows.Scan(&entry.ID, &entry.ProductID, &entry.Action, &entry.ActorID, &entry.ActorName, &changes, &entry.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve History"})
+			return
+		}
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve History"})
+			return
+		}
+		history = append(history, entry)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve History"})
+		return
+	}
+	if len(history) == 0 {
+		exists, err := productHasHistory(productID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    history,
+	})
+}
+
+// productHasHistory reports whether the product exists, deleted or not, or
+// has any recorded history.
+func productHasHistory(productID string) (bool, error) {
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1) OR EXISTS(SELECT 1 FROM product_audits WHERE productId = $1)", productID).Scan(&exists)
+	return exists, err
+}
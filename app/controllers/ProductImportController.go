@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const maxImportSize = 10 << 20
+
+// productImportColumns are the CSV columns of an import, named after the
+// JSON fields of model.ProductRequest.
+var productImportColumns = []string{"name", "sku", "categoryId", "imageUrl", "notes", "price", "stock", "location", "isAvailable"}
+
+// openImportFile returns the CSV either from the "file" field of a
+// multipart form or from the raw request body.
+func openImportFile(c *gin.Context) (io.ReadCloser, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		return header.Open()
+	}
+	return c.Request.Body, nil
+}
+
+// parseImportRow turns a CSV record into a ProductRequest and validates it
+// with the same rules as AddProduct.
+func parseImportRow(columns map[string]int, record []string) (model.ProductRequest, []string) {
+	var product model.ProductRequest
+	var errors []string
+	value := func(name string) string {
+		return strings.TrimSpace(record[columns[name]])
+	}
+
+	product.Name = value("name")
+	product.SKU = value("sku")
+	product.CategoryID = value("categoryId")
+	product.ImageURL = value("imageUrl")
+	product.Notes = value("notes")
+	product.Location = value("location")
+
+	if price, err := helper.ParseMoney(value("price")); err == nil {
+		product.Price = price
+	} else {
+		errors = append(errors, "price: must be a number")
+	}
+	if stock, err := strconv.Atoi(value("stock")); err == nil {
+		product.Stock = &stock
+	} else {
+		errors = append(errors, "stock: must be an integer")
+	}
+	if isAvailable, err := strconv.ParseBool(value("isAvailable")); err == nil {
+		product.IsAvailable = &isAvailable
+	} else {
+		errors = append(errors, "isAvailable: must be a boolean")
+	}
+	if len(errors) > 0 {
+		return product, errors
+	}
+
+	if err := binding.Validator.ValidateStruct(&product); err != nil {
+		if fieldErrors, ok := err.(validator.ValidationErrors); ok {
+			for _, fe := range fieldErrors {
+				errors = append(errors, fmt.Sprintf("%s: failed on '%s'", fe.Field(), fe.Tag()))
+			}
+		} else {
+			errors = append(errors, err.Error())
+		}
+		return product, errors
+	}
+	if !helper.ValidateURL(product.ImageURL) {
+		errors = append(errors, "ImageURL: failed on 'url'")
+	}
+	return product, errors
+}
+
+// importProduct writes one row. In upsert mode an existing product with the
+// same SKU is updated, otherwise a SKU that already exists is rejected.
+func importProduct(tx *sql.Tx, product model.ProductRequest, mode string, actorID int) (string, string, error) {
+	if err := lockSKU(tx, product.SKU); err != nil {
+		return "", "", err
+	}
+	var existingID string
+	err := tx.QueryRow("SELECT id FROM products WHERE sku = $1 AND type = 'single' AND deletedAt IS NULL ORDER BY id ASC LIMIT 1 FOR UPDATE", product.SKU).Scan(&existingID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", "", err
+	}
+
+	if existingID != "" {
+		if mode != "upsert" {
+			return "", "", &requestError{http.StatusConflict, "SKU already exists"}
+		}
+		err := withProductAudit(tx, existingID, "update", actorID, func() error {
+			return updateProduct(tx, existingID, product)
+		})
+		if err != nil {
+			return "", "", err
+		}
+		return existingID, "updated", nil
+	}
+
+	id, err := insertProduct(tx, product)
+	if err != nil {
+		return "", "", err
+	}
+	if err := auditProductCreate(tx, strconv.Itoa(id), actorID); err != nil {
+		return "", "", err
+	}
+	return strconv.Itoa(id), "created", nil
+}
+
+func ImportProduct(c *gin.Context) {
+	var params model.ProductImportParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	result := model.ProductImportResponse{
+		DryRun: params.DryRun == "true" || params.DryRun == "1",
+		Mode:   params.Mode,
+		Atomic: params.Atomic != "false" && params.Atomic != "0",
+		Rows:   []model.ProductImportRow{},
+	}
+
+	file, err := openImportFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required"})
+		return
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV header"})
+		return
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range productImportColumns {
+		if _, ok := columns[name]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing CSV column " + name})
+			return
+		}
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Import Product"})
+		return
+	}
+	defer tx.Rollback()
+
+	activeCategories := map[string]bool{}
+	for rowNumber := 2; ; rowNumber++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid CSV at row %d", rowNumber)})
+			return
+		}
+		result.Total++
+		row := model.ProductImportRow{Row: rowNumber}
+		if len(record) != len(header) {
+			row.Status = "invalid"
+			row.Errors = []string{"wrong number of columns"}
+			result.Rows = append(result.Rows, row)
+			result.Failed++
+			continue
+		}
+
+		product, errors := parseImportRow(columns, record)
+		row.SKU = product.SKU
+		if len(errors) == 0 {
+			isActive, ok := activeCategories[product.CategoryID]
+			if !ok {
+				isActive, err = categoryIsActive(product.CategoryID)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check category"})
+					return
+				}
+				activeCategories[product.CategoryID] = isActive
+			}
+			if !isActive {
+				errors = append(errors, "CategoryID: category not found")
+			}
+		}
+		if len(errors) > 0 {
+			row.Status = "invalid"
+			row.Errors = errors
+			result.Rows = append(result.Rows, row)
+			result.Failed++
+			continue
+		}
+
+		// Each row runs in its own savepoint so a failing row does not abort
+		// the rows around it.
+		if _, err := tx.Exec("SAVEPOINT import_row"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Import Product"})
+			return
+		}
+		row.ID, row.Status, err = importProduct(tx, product, params.Mode, c.GetInt("userId"))
+		if err != nil {
+			if _, rbErr := tx.Exec("ROLLBACK TO SAVEPOINT import_row"); rbErr != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Import Product"})
+				return
+			}
+			row.Status = "error"
+			if importErr, ok := err.(*requestError); ok {
+				row.Errors = []string{importErr.message}
+			} else {
+				row.Errors = []string{"failed to save row"}
+			}
+			result.Rows = append(result.Rows, row)
+			result.Failed++
+			continue
+		}
+		if _, err := tx.Exec("RELEASE SAVEPOINT import_row"); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Import Product"})
+			return
+		}
+		result.Rows = append(result.Rows, row)
+		result.Succeeded++
+	}
+
+	if result.Atomic && result.Failed > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Import rejected, no rows were saved",
+			"data":  result,
+		})
+		return
+	}
+	if !result.DryRun {
+		if err := tx.Commit(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Import Product"})
+			return
+		}
+		result.Committed = true
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    result,
+	})
+}
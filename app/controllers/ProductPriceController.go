@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+func ScheduleProductPrice(c *gin.Context) {
+	var request model.ProductPriceRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	productID := c.Param("id")
+
+	var productType string
+	var bundlePricing sql.NullString
+	err := config.DB.QueryRow("SELECT type, bundlePricing FROM products WHERE id = $1 AND deletedAt IS NULL", productID).Scan(&productType, &bundlePricing)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if productType == "bundle" && bundlePricing.String == "computed" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Price of a computed bundle follows its components"})
+		return
+	}
+	if request.EffectiveFrom != nil && request.EffectiveTo != nil && !request.EffectiveTo.After(*request.EffectiveFrom) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "effectiveTo must be after effectiveFrom"})
+		return
+	}
+
+	// A missing effectiveFrom means the price applies immediately. Periods
+	// may not start in the past, history is never rewritten.
+	var lastInsertedID int
+	err = config.DB.QueryRow(`
+    INSERT INTO product_prices (productId, price, effectiveFrom, effectiveTo, createdBy)
+    SELECT $1, $2, COALESCE($3::timestamptz, NOW()), $4::timestamptz, $5
+    WHERE COALESCE($3::timestamptz, NOW()) >= NOW()
+        AND ($4::timestamptz IS NULL OR $4::timestamptz > COALESCE($3::timestamptz, NOW()))
+    RETURNING id`,
+		productID, request.Price, request.EffectiveFrom, request.EffectiveTo, c.GetInt("userId")).Scan(&lastInsertedID)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Price period cannot start in the past"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Schedule Price"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Price scheduled successfully",
+		"data":    gin.H{"id": strconv.Itoa(lastInsertedID)},
+	})
+}
+
+func GetProductPrices(c *gin.Context) {
+	productID := c.Param("id")
+
+	exists, err := productExists(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	rows, err := config.DB.Query(`
+    SELECT pp.id, pp.productId, pp.price, pp.effectiveFrom, pp.effectiveTo, pp.createdAt,
+        CASE
+            WHEN pp.effectiveFrom > NOW() THEN 'scheduled'
+            WHEN pp.effectiveTo IS NOT NULL AND pp.effectiveTo <= NOW() THEN 'expired'
+            WHEN pp.id = (
+                SELECT cur.id FROM product_prices cur
+                WHERE cur.productId = pp.productId
+                    AND cur.effectiveFrom <= NOW()
+                    AND (cur.effectiveTo IS NULL OR cur.effectiveTo > NOW())
+                ORDER BY cur.effectiveFrom DESC, cur.id DESC
+                LIMIT 1
+            ) THEN 'active'
+            ELSE 'superseded'
+        END
+    FROM product_prices pp
+    WHERE pp.productId = $1
+    ORDER BY pp.effectiveFrom DESC, pp.id DESC`, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Prices"})
+		return
+	}
+	defer rows.Close()
+	prices := []model.ProductPriceResponse{}
+	for rows.Next() {
+		var price model.ProductPriceResponse
+		if err := rows.Scan(&price.ID, &price.ProductID, &price.Price, &price.EffectiveFrom, &price.EffectiveTo, &price.CreatedAt, &price.Status); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Prices"})
+			return
+		}
+		prices = append(prices, price)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Prices"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    prices,
+	})
+}
+
+// CancelProductPrice removes a price change that has not taken effect yet.
+// Prices that were already in effect stay as history.
+func CancelProductPrice(c *gin.Context) {
+	productID := c.Param("id")
+	priceID := c.Param("priceId")
+
+	result, err := config.DB.Exec("DELETE FROM product_prices WHERE id = $1 AND productId = $2 AND effectiveFrom > NOW()", priceID, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Cancel Price"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Scheduled price not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Cancel Price"})
+}
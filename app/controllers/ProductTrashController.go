@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+func GetDeletedProduct(c *gin.Context) {
+	var params model.GetDeletedProductParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	query := "SELECT " + productColumns + ", p.deletedAt FROM " + productSource + " p" + productJoins + " WHERE p.deletedAt IS NOT NULL"
+	args := []interface{}{}
+	if params.Name != "" {
+		query += " AND lower(p.name) LIKE $" + strconv.Itoa(len(args)+1)
+		args = append(args, "%"+strings.ToLower(params.Name)+"%")
+	}
+	if params.SKU != "" {
+		query += " AND p.sku = $" + strconv.Itoa(len(args)+1)
+		args = append(args, params.SKU)
+	}
+	query += " ORDER BY p.deletedAt DESC"
+	query += " LIMIT $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Limit)
+	query += " OFFSET $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Offset)
+
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve"})
+		return
+	}
+	defer rows.Close()
+	products := []model.ProductResponse{}
+	for rows.Next() {
+		var deletedAt time.Time
+		p, err := scanProduct(rows, &deletedAt)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve"})
+			return
+		}
+		p.DeletedAt = &deletedAt
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    products,
+	})
+}
+
+func RestoreProduct(c *gin.Context) {
+	productID := c.Param("id")
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Restore Product"})
+		return
+	}
+	defer tx.Rollback()
+
+	var sku string
+	err = tx.QueryRow("SELECT sku FROM products WHERE id = $1 AND deletedAt IS NOT NULL FOR UPDATE", productID).Scan(&sku)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Deleted product not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+
+	// The SKU may have been reused while the product was in the trash. The
+	// lock keeps an import or another restore from claiming it before commit
+	if err := lockSKU(tx, sku); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product SKU"})
+		return
+	}
+	var taken bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE sku = $1 AND id <> $2 AND deletedAt IS NULL)", sku, productID).Scan(&taken)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product SKU"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "SKU is already used by another product"})
+		return
+	}
+
+	err = withProductAudit(tx, productID, "restore", c.GetInt("userId"), func() error {
+		_, err := tx.Exec("UPDATE products SET deletedAt = NULL, version = version + 1, updatedAt = NOW() WHERE id = $1 AND deletedAt IS NOT NULL", productID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Restore Product"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Restore Product"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Restore Product"})
+}
@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"database/sql"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// validatePromotion checks the rules binding tags cannot express and that
+// the promoted product or category exists.
+func validatePromotion(promotion model.PromotionRequest) error {
+	switch promotion.Type {
+	case "percentage":
+		if promotion.Value <= 0 || promotion.Value.Float64() > 100 {
+			return &requestError{http.StatusBadRequest, "Percentage value must be between 0 and 100"}
+		}
+	case "fixed":
+		if promotion.Value <= 0 {
+			return &requestError{http.StatusBadRequest, "Fixed value must be greater than 0"}
+		}
+	case "buy_x_get_y":
+		if promotion.Scope == "basket" {
+			return &requestError{http.StatusBadRequest, "Buy X get Y needs a product or category scope"}
+		}
+		if promotion.BuyQuantity < 1 || promotion.GetQuantity < 1 {
+			return &requestError{http.StatusBadRequest, "buyQuantity and getQuantity must be at least 1"}
+		}
+	}
+	if promotion.StartsAt != nil && promotion.EndsAt != nil && !promotion.EndsAt.After(*promotion.StartsAt) {
+		return &requestError{http.StatusBadRequest, "endsAt must be after startsAt"}
+	}
+
+	switch promotion.Scope {
+	case "product":
+		exists, err := productExists(*promotion.ProductID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return &requestError{http.StatusBadRequest, "Product not found"}
+		}
+	case "category":
+		isActive, err := categoryIsActive(*promotion.CategoryID)
+		if err != nil {
+			return err
+		}
+		if !isActive {
+			return &requestError{http.StatusBadRequest, "Category not found"}
+		}
+	}
+	return nil
+}
+
+// promotionTarget returns the product and category columns to store, only
+// keeping the one that matches the scope.
+func promotionTarget(promotion model.PromotionRequest) (productID, categoryID *string) {
+	switch promotion.Scope {
+	case "product":
+		return promotion.ProductID, nil
+	case "category":
+		return nil, promotion.CategoryID
+	}
+	return nil, nil
+}
+
+func promotionCodeTaken(code string, excludeID string) (bool, error) {
+	var taken bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM promotions WHERE lower(code) = lower($1) AND id::text <> $2 AND deletedAt IS NULL)", code, excludeID).Scan(&taken)
+	return taken, err
+}
+
+func AddPromotion(c *gin.Context) {
+	var promotion model.PromotionRequest
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if err := validatePromotion(promotion); err != nil {
+		respondRequestError(c, err, "Failed to check promotion")
+		return
+	}
+	if promotion.Code != nil {
+		taken, err := promotionCodeTaken(*promotion.Code, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check promo code"})
+			return
+		}
+		if taken {
+			c.JSON(http.StatusConflict, gin.H{"error": "Promo code already exists"})
+			return
+		}
+	}
+
+	productID, categoryID := promotionTarget(promotion)
+	var lastInsertedID int
+	err := config.DB.QueryRow(`
+    INSERT INTO promotions (name, type, scope, productId, categoryId, value, buyQuantity, getQuantity, minBasketAmount, code, usageLimit, startsAt, endsAt, isActive)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+    RETURNING id`,
+		promotion.Name, promotion.Type, promotion.Scope, productID, categoryID, promotion.Value, promotion.BuyQuantity, promotion.GetQuantity,
+		promotion.MinBasketAmount, promotion.Code, promotion.UsageLimit, promotion.StartsAt, promotion.EndsAt, *promotion.IsActive).Scan(&lastInsertedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Promotion"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Promotion added successfully",
+		"data":    gin.H{"id": strconv.Itoa(lastInsertedID)},
+	})
+}
+
+func GetAllPromotion(c *gin.Context) {
+	var params model.GetPromotionParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	query := `
+    SELECT id, name, type, scope, productId::text, categoryId::text, value, buyQuantity, getQuantity, minBasketAmount,
+        code, usageLimit, usageCount, startsAt, endsAt, isActive, createdAt
+    FROM promotions WHERE deletedAt IS NULL`
+	args := []interface{}{}
+	if params.Scope != "" {
+		query += " AND scope = $" + strconv.Itoa(len(args)+1)
+		args = append(args, params.Scope)
+	}
+	if params.Code != "" {
+		query += " AND lower(code) = $" + strconv.Itoa(len(args)+1)
+		args = append(args, strings.ToLower(params.Code))
+	}
+	switch params.IsActive {
+	case "true":
+		query += " AND isActive = true"
+	case "false":
+		query += " AND isActive = false"
+	}
+	query += " ORDER BY createdAt DESC"
+	query += " LIMIT $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Limit)
+	query += " OFFSET $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Offset)
+
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Promotions"})
+		return
+	}
+	defer rows.Close()
+	promotions := []model.PromotionResponse{}
+	for rows.Next() {
+		var p model.PromotionResponse
+		if err := rows.Scan(&p.ID, &p.Name, &p.Type, &p.Scope, &p.ProductID, &p.CategoryID, &p.Value, &p.BuyQuantity, &p.GetQuantity, &p.MinBasketAmount,
+			&p.Code, &p.UsageLimit, &p.UsageCount, &p.StartsAt, &p.EndsAt, &p.IsActive, &p.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Promotions"})
+			return
+		}
+		promotions = append(promotions, p)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Promotions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    promotions,
+	})
+}
+
+func UpdatePromotion(c *gin.Context) {
+	var promotion model.PromotionRequest
+	if err := c.ShouldBindJSON(&promotion); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	promotionID := c.Param("id")
+
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM promotions WHERE id = $1 AND deletedAt IS NULL)", promotionID).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check promotion existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		return
+	}
+	if err := validatePromotion(promotion); err != nil {
+		respondRequestError(c, err, "Failed to check promotion")
+		return
+	}
+	if promotion.Code != nil {
+		taken, err := promotionCodeTaken(*promotion.Code, promotionID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check promo code"})
+			return
+		}
+		if taken {
+			c.JSON(http.StatusConflict, gin.H{"error": "Promo code already exists"})
+			return
+		}
+	}
+
+	// usageCount is kept, lowering usageLimit below it simply ends the
+	// promotion.
+	productID, categoryID := promotionTarget(promotion)
+	query := `
+    UPDATE promotions
+    SET
+        name = $1,
+        type = $2,
+        scope = $3,
+        productId = $4,
+        categoryId = $5,
+        value = $6,
+        buyQuantity = $7,
+        getQuantity = $8,
+        minBasketAmount = $9,
+        code = $10,
+        usageLimit = $11,
+        startsAt = $12,
+        endsAt = $13,
+        isActive = $14,
+        updatedAt = NOW()
+    WHERE id = $15`
+	_, err = config.DB.Exec(query, promotion.Name, promotion.Type, promotion.Scope, productID, categoryID, promotion.Value, promotion.BuyQuantity, promotion.GetQuantity,
+		promotion.MinBasketAmount, promotion.Code, promotion.UsageLimit, promotion.StartsAt, promotion.EndsAt, *promotion.IsActive, promotionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Promotion"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Promotion"})
+}
+
+func DeletePromotion(c *gin.Context) {
+	promotionID := c.Param("id")
+
+	result, err := config.DB.Exec("UPDATE promotions SET deletedAt = NOW() WHERE id = $1 AND deletedAt IS NULL", promotionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Promotion"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Delete Promotion"})
+}
+
+// loadActivePromotions returns the promotions running right now that still
+// have uses left. Promotions with a code are only included when promoCode
+// matches it, and an unknown promoCode is a client error.
+func loadActivePromotions(db dbExecutor, promoCode string) ([]helper.Promotion, error) {
+	rows, err := db.Query(`
+    SELECT id, name, type, scope, COALESCE(productId::text, ''), COALESCE(categoryId::text, ''), value, buyQuantity, getQuantity, minBasketAmount, code IS NOT NULL
+    FROM promotions
+    WHERE deletedAt IS NULL AND isActive = true
+        AND (startsAt IS NULL OR startsAt <= NOW())
+        AND (endsAt IS NULL OR endsAt > NOW())
+        AND (usageLimit IS NULL OR usageCount < usageLimit)
+        AND (code IS NULL OR lower(code) = lower($1))`, promoCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var promotions []helper.Promotion
+	codeMatched := false
+	for rows.Next() {
+		var p helper.Promotion
+		var hasCode bool
+		if err := rows.Scan(&p.ID, &p.Name, &p.Type, &p.Scope, &p.ProductID, &p.CategoryID, &p.Value, &p.BuyQuantity, &p.GetQuantity, &p.MinBasketAmount, &hasCode); err != nil {
+			return nil, err
+		}
+		codeMatched = codeMatched || hasCode
+		promotions = append(promotions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if promoCode != "" && !codeMatched {
+		return nil, &requestError{http.StatusBadRequest, "Invalid promo code"}
+	}
+	return promotions, nil
+}
+
+// productCategoryChains returns, per product, its category followed by all
+// of that category's ancestors.
+func productCategoryChains(db dbExecutor, productIDs []string) (map[string][]string, error) {
+	rows, err := db.Query(`
+    WITH RECURSIVE chain AS (
+        SELECT p.id AS productId, c.id, c.parentId
+        FROM products p JOIN categories c ON c.id = p.categoryId
+        WHERE p.id = ANY($1::int[])
+        UNION ALL
+        SELECT ch.productId, c.id, c.parentId
+        FROM categories c JOIN chain ch ON c.id = ch.parentId
+    )
+    SELECT productId::text, id::text FROM chain`, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	chains := map[string][]string{}
+	for rows.Next() {
+		var productID, categoryID string
+		if err := rows.Scan(&productID, &categoryID); err != nil {
+			return nil, err
+		}
+		chains[productID] = append(chains[productID], categoryID)
+	}
+	return chains, rows.Err()
+}
+
+// applyPromotions evaluates the active promotions against the checkout lines,
+// filling in each line's discount, and returns the breakdown. Promotion
+// amounts are in the base currency; for a sale in another currency they are
+// converted at rate.
+func applyPromotions(tx *sql.Tx, lines []checkoutLine, promoCode string, rate *big.Rat) (helper.PriceBreakdown, error) {
+	promotions, err := loadActivePromotions(tx, promoCode)
+	if err != nil {
+		return helper.PriceBreakdown{}, err
+	}
+	if rate != nil {
+		for i, p := range promotions {
+			if p.Type == "fixed" {
+				promotions[i].Value = p.Value.FromBase(rate)
+			}
+			promotions[i].MinBasketAmount = p.MinBasketAmount.FromBase(rate)
+		}
+	}
+	productIDs := make([]string, len(lines))
+	for i, line := range lines {
+		productIDs[i] = line.ProductID
+	}
+	chains, err := productCategoryChains(tx, productIDs)
+	if err != nil {
+		return helper.PriceBreakdown{}, err
+	}
+
+	promotionLines := make([]helper.PromotionLine, len(lines))
+	for i, line := range lines {
+		promotionLines[i] = helper.PromotionLine{
+			ProductID:   line.ProductID,
+			CategoryIDs: chains[line.ProductID],
+			Quantity:    line.Quantity,
+			UnitPrice:   line.Price,
+		}
+	}
+	breakdown := helper.EvaluatePromotions(promotionLines, promotions)
+	for i := range lines {
+		lines[i].Discount = breakdown.LineDiscounts[i]
+	}
+	return breakdown, nil
+}
+
+// appliedDiscounts turns the engine's discounts into their response form.
+func appliedDiscounts(lines []checkoutLine, discounts []helper.Discount) []model.AppliedDiscount {
+	applied := make([]model.AppliedDiscount, 0, len(discounts))
+	for _, d := range discounts {
+		entry := model.AppliedDiscount{PromotionID: d.PromotionID, Name: d.Name, Amount: d.Amount}
+		if d.LineIndex >= 0 {
+			entry.ProductID = lines[d.LineIndex].ProductID
+			entry.VariantID = lines[d.LineIndex].VariantID
+		}
+		applied = append(applied, entry)
+	}
+	return applied
+}
+
+// redeemPromotions counts one use of every promotion applied to a sale. It
+// fails when another sale used up a limited promotion in the meantime.
+func redeemPromotions(tx *sql.Tx, discounts []helper.Discount) error {
+	redeemed := map[string]bool{}
+	for _, d := range discounts {
+		if redeemed[d.PromotionID] {
+			continue
+		}
+		redeemed[d.PromotionID] = true
+		result, err := tx.Exec("UPDATE promotions SET usageCount = usageCount + 1 WHERE id = $1 AND (usageLimit IS NULL OR usageCount < usageLimit)", d.PromotionID)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return &requestError{http.StatusConflict, "Promotion usage limit reached"}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+var paymentMethodLabels = map[string]string{
+	"cash":         "Cash",
+	"card":         "Card",
+	"ewallet":      "E-wallet",
+	"store_credit": "Store credit",
+	"gift_card":    "Gift card",
+	"points":       "Points",
+}
+
+// receiptTemplateData is what the RECEIPT_HEADER and RECEIPT_FOOTER
+// templates can refer to.
+type receiptTemplateData struct {
+	TransactionID string
+	Date          string
+	Staff         string
+	Customer      string
+	Currency      string
+}
+
+// renderReceiptTemplate executes a header or footer template from the
+// environment. A literal \n in the setting starts a new line.
+func renderReceiptTemplate(key string, def string, data receiptTemplateData) ([]string, error) {
+	text := strings.ReplaceAll(config.GetEnv(key, def), `\n`, "\n")
+	tmpl, err := template.New(key).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimRight(b.String(), "\n"), "\n"), nil
+}
+
+// receiptItemNames names the lines of a transaction: the product name,
+// followed by the option values of the variant in the product's option
+// order.
+func receiptItemNames(details []model.TransactionDetail) (map[string]string, map[string]string, error) {
+	var productIDs, variantIDs []string
+	for _, d := range details {
+		productIDs = append(productIDs, d.ProductID)
+		if d.VariantID != "" {
+			variantIDs = append(variantIDs, d.VariantID)
+		}
+	}
+
+	products := map[string]string{}
+	rows, err := config.DB.Query("SELECT id, name FROM products WHERE id = ANY($1::int[])", pq.Array(productIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, nil, err
+		}
+		products[id] = name
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	variants := map[string]string{}
+	variantRows, err := config.DB.Query(`
+    SELECT v.id, string_agg(v.options->>po.name, ', ' ORDER BY po.position)
+    FROM product_variants v
+    JOIN product_options po ON po.productId = v.productId
+    WHERE v.id = ANY($1::int[])
+    GROUP BY v.id`, pq.Array(variantIDs))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer variantRows.Close()
+	for variantRows.Next() {
+		var id string
+		var options sql.NullString
+		if err := variantRows.Scan(&id, &options); err != nil {
+			return nil, nil, err
+		}
+		variants[id] = options.String
+	}
+	return products, variants, variantRows.Err()
+}
+
+// buildReceipt lays out a transaction, with its details attached, for
+// printing.
+func buildReceipt(t model.TransactionResponse, staff, customer string) (helper.Receipt, error) {
+	data := receiptTemplateData{
+		TransactionID: t.TransactionID,
+		Date:          t.CreatedAt.Format("2006-01-02 15:04"),
+		Staff:         staff,
+		Customer:      customer,
+		Currency:      t.Currency,
+	}
+	var receipt helper.Receipt
+	var err error
+	if receipt.Header, err = renderReceiptTemplate("RECEIPT_HEADER", "EniQilo Store", data); err != nil {
+		return receipt, err
+	}
+	if receipt.Footer, err = renderReceiptTemplate("RECEIPT_FOOTER", "Thank you for shopping!", data); err != nil {
+		return receipt, err
+	}
+
+	receipt.Info = []string{"Transaction #" + t.TransactionID, data.Date}
+	if staff != "" {
+		receipt.Info = append(receipt.Info, "Cashier: "+staff)
+	}
+	if customer != "" {
+		receipt.Info = append(receipt.Info, "Customer: "+customer)
+	}
+	if t.Currency != baseCurrency() {
+		receipt.Info = append(receipt.Info, "Currency: "+t.Currency+" at "+t.ExchangeRate.String()+" "+baseCurrency())
+	}
+
+	products, variants, err := receiptItemNames(t.ProductDetails)
+	if err != nil {
+		return receipt, err
+	}
+	var lineDiscounts helper.Money
+	for _, d := range t.ProductDetails {
+		name := products[d.ProductID]
+		if options := variants[d.VariantID]; options != "" {
+			name += " (" + options + ")"
+		}
+		var promotions []string
+		for _, discount := range t.Discounts {
+			if discount.ProductID == d.ProductID && discount.VariantID == d.VariantID {
+				promotions = append(promotions, discount.Name)
+			}
+		}
+		label := strings.Join(promotions, ", ")
+		if label == "" {
+			label = "Discount"
+		}
+		receipt.Lines = append(receipt.Lines, helper.ReceiptLine{
+			Name:          name,
+			Quantity:      d.Quantity,
+			Price:         d.Price,
+			Total:         d.Total,
+			Discount:      d.Discount,
+			DiscountLabel: label,
+		})
+		lineDiscounts += d.Discount
+	}
+
+	receipt.Totals = []helper.ReceiptAmount{{Label: "Subtotal", Amount: t.Subtotal}}
+	if lineDiscounts != 0 {
+		receipt.Totals = append(receipt.Totals, helper.ReceiptAmount{Label: "Item discounts", Amount: -lineDiscounts})
+	}
+	for _, discount := range t.Discounts {
+		if discount.ProductID == "" {
+			receipt.Totals = append(receipt.Totals, helper.ReceiptAmount{Label: discount.Name, Amount: -discount.Amount})
+		}
+	}
+	if t.TaxMode == "inclusive" {
+		receipt.Totals = append(receipt.Totals, helper.ReceiptAmount{Label: "Tax included", Amount: t.Tax})
+	} else {
+		receipt.Totals = append(receipt.Totals, helper.ReceiptAmount{Label: "Tax", Amount: t.Tax})
+	}
+	receipt.Total = helper.ReceiptAmount{Label: "TOTAL " + t.Currency, Amount: t.Total}
+
+	for _, payment := range t.Payments {
+		label := paymentMethodLabels[payment.Method]
+		reference := paymentReference(payment.Method, payment.Reference)
+		if reference != "" {
+			label += " (" + reference + ")"
+		}
+		receipt.Payments = append(receipt.Payments, helper.ReceiptAmount{Label: label, Amount: payment.Amount})
+	}
+	receipt.Change = t.Change
+
+	if t.VoidedAt != nil {
+		receipt.Notices = append(receipt.Notices, "VOIDED: "+t.VoidReason)
+	}
+	switch t.RefundStatus {
+	case "partial":
+		receipt.Notices = append(receipt.Notices, "PARTIALLY REFUNDED")
+	case "refunded":
+		receipt.Notices = append(receipt.Notices, "REFUNDED")
+	}
+	return receipt, nil
+}
+
+// GetTransactionReceipt renders the receipt of a transaction as fixed-width
+// text (the default), HTML or an ESC/POS byte stream. Text and ESC/POS
+// lines are RECEIPT_WIDTH characters wide.
+func GetTransactionReceipt(c *gin.Context) {
+	var params model.GetReceiptParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	transactionID := c.Param("id")
+	if _, err := strconv.Atoi(transactionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	var staff, customer string
+	t, err := scanTransaction(func(dest ...interface{}) error {
+		return config.DB.QueryRow(`
+    SELECT `+transactionColumns+`, COALESCE(s.name, ''), COALESCE(cu.name, '')
+    FROM transactions
+    LEFT JOIN users s ON s.id = transactions.staffId
+    LEFT JOIN users cu ON cu.id = transactions.customerId
+    WHERE transactions.id = $1`, transactionID).Scan(append(dest, &staff, &customer)...)
+	})
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Receipt"})
+		return
+	}
+	transactions := []model.TransactionResponse{t}
+	if err := attachTransactionDetails(transactions); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Receipt"})
+		return
+	}
+	receipt, err := buildReceipt(transactions[0], staff, customer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Receipt"})
+		return
+	}
+
+	width := config.GetEnvInt("RECEIPT_WIDTH", 42)
+	switch params.Format {
+	case "html":
+		page, err := receipt.HTML()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Receipt"})
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	case "escpos":
+		c.Header("Content-Disposition", `attachment; filename="receipt-`+transactionID+`.bin"`)
+		c.Data(http.StatusOK, "application/octet-stream", receipt.ESCPOS(width))
+	default:
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(receipt.Text(width)))
+	}
+}
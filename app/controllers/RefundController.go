@@ -0,0 +1,502 @@
+package controller
+
+import (
+	"database/sql"
+	"math/big"
+	"net/http"
+	"strconv"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// refundableLine is a sold line together with how much of it has already
+// been refunded.
+type refundableLine struct {
+	detailID         int
+	productID        string
+	variantID        string
+	productType      string
+	quantity         int
+	netTotal         helper.Money
+	refundedQuantity int
+	refundedAmount   helper.Money
+}
+
+func (l refundableLine) remaining() int {
+	return l.quantity - l.refundedQuantity
+}
+
+// refundAmount is what refunding quantity more items of the line pays back.
+// The last items refunded take whatever is left of the line, so a line
+// refunded in parts never pays back more or less than was paid for it.
+func (l refundableLine) refundAmount(quantity int) helper.Money {
+	if l.refundedQuantity+quantity == l.quantity {
+		return l.netTotal - l.refundedAmount
+	}
+	return l.netTotal.Share(helper.Money(quantity), helper.Money(l.quantity))
+}
+
+func loadRefundableLines(tx *sql.Tx, transactionID string) ([]refundableLine, error) {
+	rows, err := tx.Query(`
+    SELECT td.id, td.productId, COALESCE(td.variantId::text, ''), p.type, td.quantity, td.netTotal,
+        COALESCE(SUM(ri.quantity), 0), COALESCE(SUM(ri.amount), 0)
+    FROM transaction_details td
+    JOIN products p ON p.id = td.productId
+    LEFT JOIN refund_items ri ON ri.transactionDetailId = td.id
+    WHERE td.transactionId = $1
+    GROUP BY td.id, p.type
+    ORDER BY td.id ASC`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var lines []refundableLine
+	for rows.Next() {
+		var l refundableLine
+		if err := rows.Scan(&l.detailID, &l.productID, &l.variantID, &l.productType, &l.quantity, &l.netTotal, &l.refundedQuantity, &l.refundedAmount); err != nil {
+			return nil, err
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+// allocateRefund decides how many items of every line are returned. Items
+// matching several lines of the sale are taken from the lines in order.
+func allocateRefund(lines []refundableLine, items []model.RefundItemRequest) (map[int]int, error) {
+	allocation := map[int]int{}
+	if len(items) == 0 {
+		for i, line := range lines {
+			if line.remaining() > 0 {
+				allocation[i] = line.remaining()
+			}
+		}
+		return allocation, nil
+	}
+
+	for _, item := range items {
+		left := item.Quantity
+		matched := false
+		for i, line := range lines {
+			if line.productID != item.ProductID || line.variantID != item.VariantID {
+				continue
+			}
+			matched = true
+			take := line.remaining() - allocation[i]
+			if take > left {
+				take = left
+			}
+			if take > 0 {
+				allocation[i] += take
+				left -= take
+			}
+		}
+		if !matched {
+			return nil, &requestError{http.StatusBadRequest, "Product was not part of the transaction"}
+		}
+		if left > 0 {
+			return nil, &requestError{http.StatusBadRequest, "Refund quantity exceeds the quantity left to refund"}
+		}
+	}
+	return allocation, nil
+}
+
+// tenderBalance is what is left to refund of one tender of a sale, in the
+// sale currency.
+type tenderBalance struct {
+	method    string
+	reference string
+	amount    helper.Money
+}
+
+// loadTenderBalances works out what is left to refund of every tender of a
+// sale: what was paid with it, less the cash handed back as change and
+// what earlier refunds already paid back through it.
+func loadTenderBalances(tx *sql.Tx, transactionID string, change helper.Money) ([]tenderBalance, error) {
+	rows, err := tx.Query(`
+    SELECT p.method, p.reference, p.amount, COALESCE((
+        SELECT SUM(rt.amount) FROM refund_tenders rt
+        JOIN refunds r ON r.id = rt.refundId
+        WHERE r.transactionId = $1 AND rt.method = p.method AND COALESCE(rt.reference, '') = p.reference), 0)
+    FROM (
+        SELECT method, COALESCE(reference, '') AS reference, SUM(amount) AS amount, MIN(id) AS id
+        FROM transaction_payments
+        WHERE transactionId = $1
+        GROUP BY method, COALESCE(reference, '')
+    ) p
+    ORDER BY p.id ASC`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var balances []tenderBalance
+	for rows.Next() {
+		var b tenderBalance
+		var refunded helper.Money
+		if err := rows.Scan(&b.method, &b.reference, &b.amount, &refunded); err != nil {
+			return nil, err
+		}
+		if b.method == "cash" {
+			given := helper.MinMoney(change, b.amount)
+			b.amount -= given
+			change -= given
+		}
+		b.amount -= refunded
+		if b.amount < 0 {
+			b.amount = 0
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// allocateRefundTenders splits a refund over the tenders it is paid back
+// through. A cash, card or e-wallet refund pays back at most what is left
+// of that tender; the rest goes back to the gift cards and points the sale
+// was paid with and, after those, to store credit. Refunds to store credit
+// are not limited.
+func allocateRefundTenders(amount helper.Money, tender string, balances []tenderBalance) []model.RefundTenderResponse {
+	tenders := []model.RefundTenderResponse{}
+	left := amount
+	take := func(methods ...string) {
+		for _, b := range balances {
+			if left == 0 {
+				return
+			}
+			matched := false
+			for _, method := range methods {
+				matched = matched || b.method == method
+			}
+			if !matched || b.amount <= 0 {
+				continue
+			}
+			part := helper.MinMoney(b.amount, left)
+			tenders = append(tenders, model.RefundTenderResponse{Method: b.method, Reference: b.reference, Amount: part})
+			left -= part
+		}
+	}
+	if tender != "store_credit" {
+		take(tender)
+		take("gift_card", "points")
+	}
+	if left > 0 {
+		tenders = append(tenders, model.RefundTenderResponse{Method: "store_credit", Amount: left})
+	}
+	return tenders
+}
+
+// issueRefund pays one part of a refund back through its tender. Cash,
+// card and e-wallet refunds are paid outside the system. A gift card that
+// has expired since the sale is refunded to store credit instead.
+func issueRefund(tx *sql.Tx, transactionID string, refundID int, customerID string, tender *model.RefundTenderResponse) error {
+	switch tender.Method {
+	case "gift_card":
+		var giftCardID int
+		var expired bool
+		err := tx.QueryRow("SELECT id, COALESCE(expiresAt <= NOW(), false) FROM gift_cards WHERE code = $1 AND kind = 'gift_card' FOR UPDATE",
+			normalizeGiftCardCode(tender.Reference)).Scan(&giftCardID, &expired)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == nil && !expired {
+			return changeGiftCardBalance(tx, giftCardID, "refund", tender.BaseAmount, transactionID, refundID)
+		}
+		tender.Method, tender.Reference = "store_credit", ""
+		return creditStoreCredit(tx, customerID, transactionID, refundID, tender.BaseAmount)
+	case "points":
+		return refundRedeemedPoints(tx, transactionID, refundID, customerID, tender.BaseAmount)
+	case "store_credit":
+		return creditStoreCredit(tx, customerID, transactionID, refundID, tender.BaseAmount)
+	}
+	return nil
+}
+
+// issueRefundTenders pays a refund back through its tenders and records
+// them. The base amounts of the parts add up to baseAmount.
+func issueRefundTenders(tx *sql.Tx, transactionID string, refundID int, customerID string, tenders []model.RefundTenderResponse, baseAmount helper.Money, rate *big.Rat) error {
+	for i := range tenders {
+		tender := &tenders[i]
+		if i == len(tenders)-1 {
+			tender.BaseAmount = baseAmount
+		} else {
+			tender.BaseAmount = tender.Amount.ToBase(rate)
+		}
+		baseAmount -= tender.BaseAmount
+		if err := issueRefund(tx, transactionID, refundID, customerID, tender); err != nil {
+			return err
+		}
+		var reference interface{}
+		if tender.Reference != "" {
+			reference = tender.Reference
+		}
+		_, err := tx.Exec("INSERT INTO refund_tenders (refundId, method, amount, baseAmount, reference) VALUES ($1, $2, $3, $4, $5)",
+			refundID, tender.Method, tender.Amount, tender.BaseAmount, reference)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func RefundTransaction(c *gin.Context) {
+	var request model.RefundRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	transactionID := c.Param("id")
+	if _, err := strconv.Atoi(transactionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	// Locking the transaction serializes refunds of the same sale
+	var customerID, exchangeRate, refundStatus string
+	var change helper.Money
+	var voided bool
+	err = tx.QueryRow("SELECT customerId, exchangeRate::text, change, refundStatus, voidedAt IS NOT NULL FROM transactions WHERE id = $1 FOR UPDATE", transactionID).Scan(&customerID, &exchangeRate, &change, &refundStatus, &voided)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+	if voided {
+		c.JSON(http.StatusConflict, gin.H{"error": "Voided transactions cannot be refunded"})
+		return
+	}
+	if refundStatus == "refunded" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Transaction is already fully refunded"})
+		return
+	}
+	rate, err := helper.ParseExchangeRate(exchangeRate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+
+	lines, err := loadRefundableLines(tx, transactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+	allocation, err := allocateRefund(lines, request.Items)
+	if err != nil {
+		respondRequestError(c, err, "Error when Refund Transaction")
+		return
+	}
+
+	var amount helper.Money
+	amounts := map[int]helper.Money{}
+	for i, quantity := range allocation {
+		amounts[i] = lines[i].refundAmount(quantity)
+		amount += amounts[i]
+	}
+	balances, err := loadTenderBalances(tx, transactionID, change)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+	tenders := allocateRefundTenders(amount, request.Tender, balances)
+
+	var refundID int
+	var createdAt time.Time
+	err = tx.QueryRow(`
+    INSERT INTO refunds (transactionId, staffId, reason, tender, amount, baseAmount, restocked)
+    VALUES ($1, $2, $3, $4, $5, $6, $7)
+    RETURNING id, createdAt`,
+		transactionID, c.GetInt("userId"), request.Reason, request.Tender, amount, amount.ToBase(rate), request.Restock).Scan(&refundID, &createdAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+
+	components, err := soldComponents(tx, transactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+	items := []model.RefundItemResponse{}
+	var restock []checkoutLine
+	fullyRefunded := true
+	for i, line := range lines {
+		quantity := allocation[i]
+		if line.remaining() > quantity {
+			fullyRefunded = false
+		}
+		if quantity == 0 {
+			continue
+		}
+		_, err := tx.Exec("INSERT INTO refund_items (refundId, transactionDetailId, quantity, amount) VALUES ($1, $2, $3, $4)",
+			refundID, line.detailID, quantity, amounts[i])
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+			return
+		}
+		items = append(items, model.RefundItemResponse{ProductID: line.productID, VariantID: line.variantID, Quantity: quantity, Amount: amounts[i]})
+		restock = append(restock, checkoutLine{ProductID: line.productID, VariantID: line.variantID, Type: line.productType, Quantity: quantity, Components: components[line.detailID]})
+	}
+	if len(items) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Nothing left to refund"})
+		return
+	}
+
+	if request.Restock {
+		if err := restockLines(tx, restock); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+			return
+		}
+	}
+	if err := issueRefundTenders(tx, transactionID, refundID, customerID, tenders, amount.ToBase(rate), rate); err != nil {
+		respondRequestError(c, err, "Error when Refund Transaction")
+		return
+	}
+	if err := reverseRefundPoints(tx, transactionID, refundID, customerID, fullyRefunded); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+
+	for i := range tenders {
+		tenders[i].Reference = paymentReference(tenders[i].Method, tenders[i].Reference)
+	}
+
+	refundStatus = "partial"
+	if fullyRefunded {
+		refundStatus = "refunded"
+	}
+	if _, err := tx.Exec("UPDATE transactions SET refundStatus = $1 WHERE id = $2", refundStatus, transactionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Refund Transaction"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Refund created successfully",
+		"data": model.RefundResponse{
+			ID:            strconv.Itoa(refundID),
+			TransactionID: transactionID,
+			Reason:        request.Reason,
+			Tender:        request.Tender,
+			Amount:        amount,
+			BaseAmount:    amount.ToBase(rate),
+			Restocked:     request.Restock,
+			Items:         items,
+			Tenders:       tenders,
+			RefundStatus:  refundStatus,
+			CreatedAt:     createdAt,
+		},
+	})
+}
+
+// attachRefundTenders fills in the tenders of the refunds of a transaction.
+func attachRefundTenders(transactionID string, refunds []model.RefundResponse) error {
+	rows, err := config.DB.Query(`
+    SELECT rt.refundId, rt.method, rt.amount, rt.baseAmount, COALESCE(rt.reference, '')
+    FROM refund_tenders rt
+    JOIN refunds r ON r.id = rt.refundId
+    WHERE r.transactionId = $1
+    ORDER BY rt.id ASC`, transactionID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	tenders := map[string][]model.RefundTenderResponse{}
+	for rows.Next() {
+		var refundID string
+		var t model.RefundTenderResponse
+		if err := rows.Scan(&refundID, &t.Method, &t.Amount, &t.BaseAmount, &t.Reference); err != nil {
+			return err
+		}
+		t.Reference = paymentReference(t.Method, t.Reference)
+		tenders[refundID] = append(tenders[refundID], t)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for i := range refunds {
+		refunds[i].Tenders = tenders[refunds[i].ID]
+		if refunds[i].Tenders == nil {
+			refunds[i].Tenders = []model.RefundTenderResponse{}
+		}
+	}
+	return nil
+}
+
+func GetTransactionRefunds(c *gin.Context) {
+	transactionID := c.Param("id")
+	if _, err := strconv.Atoi(transactionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	var refundStatus string
+	err := config.DB.QueryRow("SELECT refundStatus FROM transactions WHERE id = $1", transactionID).Scan(&refundStatus)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check transaction existence"})
+		return
+	}
+
+	rows, err := config.DB.Query(`
+    SELECT r.id, r.reason, r.tender, r.amount, r.baseAmount, r.restocked, r.createdAt,
+        td.productId, COALESCE(td.variantId::text, ''), ri.quantity, ri.amount
+    FROM refunds r
+    JOIN refund_items ri ON ri.refundId = r.id
+    JOIN transaction_details td ON td.id = ri.transactionDetailId
+    WHERE r.transactionId = $1
+    ORDER BY r.id ASC, ri.id ASC`, transactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Refunds"})
+		return
+	}
+	defer rows.Close()
+	refunds := []model.RefundResponse{}
+	for rows.Next() {
+		var r model.RefundResponse
+		var item model.RefundItemResponse
+		if err := rows.Scan(&r.ID, &r.Reason, &r.Tender, &r.Amount, &r.BaseAmount, &r.Restocked, &r.CreatedAt,
+			&item.ProductID, &item.VariantID, &item.Quantity, &item.Amount); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Refunds"})
+			return
+		}
+		if n := len(refunds); n > 0 && refunds[n-1].ID == r.ID {
+			refunds[n-1].Items = append(refunds[n-1].Items, item)
+			continue
+		}
+		r.TransactionID = transactionID
+		r.RefundStatus = refundStatus
+		r.Items = []model.RefundItemResponse{item}
+		refunds = append(refunds, r)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Refunds"})
+		return
+	}
+	if err := attachRefundTenders(transactionID, refunds); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Refunds"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    refunds,
+	})
+}
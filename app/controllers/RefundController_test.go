@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+func TestAllocateRefundTenders(t *testing.T) {
+	balances := []tenderBalance{
+		{method: "card", reference: "APPROVAL1", amount: 3000},
+		{method: "gift_card", reference: "GIFT1234", amount: 2000},
+		{method: "cash", amount: 1000},
+		{method: "points", amount: 500},
+	}
+	tests := []struct {
+		name   string
+		amount helper.Money
+		tender string
+		want   []model.RefundTenderResponse
+	}{
+		{"within card", 2500, "card", []model.RefundTenderResponse{
+			{Method: "card", Reference: "APPROVAL1", Amount: 2500},
+		}},
+		{"card then gift card and points", 6000, "card", []model.RefundTenderResponse{
+			{Method: "card", Reference: "APPROVAL1", Amount: 3000},
+			{Method: "gift_card", Reference: "GIFT1234", Amount: 2000},
+			{Method: "points", Amount: 500},
+			{Method: "store_credit", Amount: 500},
+		}},
+		{"cash never paid by card", 1500, "cash", []model.RefundTenderResponse{
+			{Method: "cash", Amount: 1000},
+			{Method: "gift_card", Reference: "GIFT1234", Amount: 500},
+		}},
+		{"ewallet not used", 800, "ewallet", []model.RefundTenderResponse{
+			{Method: "gift_card", Reference: "GIFT1234", Amount: 800},
+		}},
+		{"store credit is not limited", 9000, "store_credit", []model.RefundTenderResponse{
+			{Method: "store_credit", Amount: 9000},
+		}},
+		{"nothing to refund", 0, "cash", []model.RefundTenderResponse{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateRefundTenders(tt.amount, tt.tender, balances)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			var sum helper.Money
+			for _, tender := range got {
+				sum += tender.Amount
+			}
+			if sum != tt.amount {
+				t.Errorf("tenders add up to %v, want %v", sum, tt.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+const suspendedCartColumns = "id, staffId, COALESCE(customerId::text, ''), productDetails, COALESCE(promoCode, ''), COALESCE(currency, ''), COALESCE(note, ''), expiresAt, createdAt"
+
+// scanSuspendedCart reads the suspendedCartColumns of a row with scan,
+// which is the Scan method of a *sql.Row or *sql.Rows.
+func scanSuspendedCart(scan func(dest ...interface{}) error) (model.SuspendedCartResponse, error) {
+	var cart model.SuspendedCartResponse
+	var details []byte
+	if err := scan(&cart.ID, &cart.StaffID, &cart.CustomerID, &details, &cart.PromoCode, &cart.Currency, &cart.Note, &cart.ExpiresAt, &cart.CreatedAt); err != nil {
+		return cart, err
+	}
+	err := json.Unmarshal(details, &cart.ProductDetails)
+	return cart, err
+}
+
+// validateSuspendedCart checks that a parked basket could be checked out as
+// it is, so a cart that can never be resumed is not stored.
+func validateSuspendedCart(request model.SuspendCartRequest) error {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := buildCheckoutLines(tx, request.ProductDetails); err != nil {
+		return err
+	}
+	if request.PromoCode != "" {
+		if _, err := loadActivePromotions(tx, request.PromoCode); err != nil {
+			return err
+		}
+	}
+	_, _, err = resolveSaleCurrency(tx, request.Currency)
+	return err
+}
+
+func SuspendCart(c *gin.Context) {
+	var request model.SuspendCartRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	var customerID interface{}
+	if request.CustomerID != "" {
+		exists, err := customerExists(request.CustomerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check customer"})
+			return
+		}
+		if !exists {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+			return
+		}
+		customerID = request.CustomerID
+	}
+	if err := validateSuspendedCart(request); err != nil {
+		respondRequestError(c, err, "Error when Suspend Cart")
+		return
+	}
+
+	details, err := json.Marshal(request.ProductDetails)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Suspend Cart"})
+		return
+	}
+	var promoCode, currency, note interface{}
+	if request.PromoCode != "" {
+		promoCode = request.PromoCode
+	}
+	if request.Currency != "" {
+		currency = request.Currency
+	}
+	if request.Note != "" {
+		note = request.Note
+	}
+
+	ttlMinutes := config.GetEnvInt("SUSPENDED_CART_TTL_MINUTES", 240)
+	cart, err := scanSuspendedCart(config.DB.QueryRow(`
+    INSERT INTO suspended_carts (staffId, customerId, productDetails, promoCode, currency, note, expiresAt)
+    VALUES ($1, $2, $3, $4, $5, $6, NOW() + make_interval(mins => $7))
+    RETURNING `+suspendedCartColumns,
+		c.GetInt("userId"), customerID, details, promoCode, currency, note, ttlMinutes).Scan)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Suspend Cart"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Cart suspended successfully",
+		"data":    cart,
+	})
+}
+
+// GetSuspendedCarts lists the carts that can still be resumed, from any
+// terminal.
+func GetSuspendedCarts(c *gin.Context) {
+	var params model.GetSuspendedCartParams
+	if err := c.BindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+
+	query := "SELECT " + suspendedCartColumns + " FROM suspended_carts WHERE resumedAt IS NULL AND expiresAt > NOW()"
+	args := []interface{}{}
+	if params.CustomerID != "" {
+		query += " AND customerId = $" + strconv.Itoa(len(args)+1)
+		args = append(args, params.CustomerID)
+	}
+	query += " ORDER BY createdAt DESC"
+	query += " LIMIT $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Limit)
+	query += " OFFSET $" + strconv.Itoa(len(args)+1)
+	args = append(args, params.Offset)
+
+	rows, err := config.DB.Query(query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Suspended Carts"})
+		return
+	}
+	defer rows.Close()
+	carts := []model.SuspendedCartResponse{}
+	for rows.Next() {
+		cart, err := scanSuspendedCart(rows.Scan)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Suspended Carts"})
+			return
+		}
+		carts = append(carts, cart)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Suspended Carts"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    carts,
+	})
+}
+
+// ResumeSuspendedCart takes a cart off the suspended list and returns its
+// contents for checkout. A cart can be resumed once, so two terminals never
+// ring up the same basket.
+func ResumeSuspendedCart(c *gin.Context) {
+	cartID := c.Param("id")
+	if _, err := strconv.Atoi(cartID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suspended cart not found"})
+		return
+	}
+
+	cart, err := scanSuspendedCart(config.DB.QueryRow(`
+    UPDATE suspended_carts SET resumedAt = NOW(), resumedBy = $2
+    WHERE id = $1 AND resumedAt IS NULL AND expiresAt > NOW()
+    RETURNING `+suspendedCartColumns, cartID, c.GetInt("userId")).Scan)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suspended cart not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Resume Cart"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    cart,
+	})
+}
+
+func DeleteSuspendedCart(c *gin.Context) {
+	cartID := c.Param("id")
+	if _, err := strconv.Atoi(cartID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suspended cart not found"})
+		return
+	}
+
+	result, err := config.DB.Exec("DELETE FROM suspended_carts WHERE id = $1 AND resumedAt IS NULL", cartID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Suspended Cart"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Suspended cart not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Delete Suspended Cart"})
+}
@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// lineTax is the tax class a product is taxed under.
+type lineTax struct {
+	classID string
+	rate    float64
+}
+
+// taxRules reads the store's tax settings from the environment.
+func taxRules() helper.TaxRules {
+	return helper.TaxRules{
+		Inclusive: config.GetEnv("TAX_MODE", "exclusive") == "inclusive",
+		Rounding:  config.GetEnv("TAX_ROUNDING", "half_up"),
+		PerLine:   config.GetEnv("TAX_ROUNDING_LEVEL", "line") == "line",
+	}
+}
+
+func taxMode(rules helper.TaxRules) string {
+	if rules.Inclusive {
+		return "inclusive"
+	}
+	return "exclusive"
+}
+
+func taxClassExists(taxClassID string) (bool, error) {
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM tax_classes WHERE id = $1 AND deletedAt IS NULL)", taxClassID).Scan(&exists)
+	return exists, err
+}
+
+// productTaxClasses resolves the tax class of every product: its own class,
+// else the class of the closest category up the tree that has one. Products
+// without any class are not in the result and are not taxed.
+func productTaxClasses(db dbExecutor, productIDs []string) (map[string]lineTax, error) {
+	rows, err := db.Query(`
+    WITH RECURSIVE chain AS (
+        SELECT p.id AS productId, c.parentId, c.taxClassId, 1 AS depth
+        FROM products p JOIN categories c ON c.id = p.categoryId
+        WHERE p.id = ANY($1::int[])
+        UNION ALL
+        SELECT ch.productId, c.parentId, c.taxClassId, ch.depth + 1
+        FROM categories c JOIN chain ch ON c.id = ch.parentId
+    ), classes AS (
+        SELECT id AS productId, taxClassId, 0 AS depth FROM products WHERE id = ANY($1::int[])
+        UNION ALL
+        SELECT productId, taxClassId, depth FROM chain
+    )
+    SELECT DISTINCT ON (cl.productId) cl.productId::text, tc.id::text, tc.rate
+    FROM classes cl JOIN tax_classes tc ON tc.id = cl.taxClassId AND tc.deletedAt IS NULL
+    ORDER BY cl.productId, cl.depth`, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	classes := map[string]lineTax{}
+	for rows.Next() {
+		var productID string
+		var tax lineTax
+		if err := rows.Scan(&productID, &tax.classID, &tax.rate); err != nil {
+			return nil, err
+		}
+		classes[productID] = tax
+	}
+	return classes, rows.Err()
+}
+
+// applyTax taxes every line on its amount after discounts, filling in the
+// line's tax and net total, and returns the transaction totals.
+func applyTax(tx *sql.Tx, lines []checkoutLine, breakdown helper.PriceBreakdown) (helper.TaxBreakdown, error) {
+	productIDs := make([]string, len(lines))
+	for i, line := range lines {
+		productIDs[i] = line.ProductID
+	}
+	classes, err := productTaxClasses(tx, productIDs)
+	if err != nil {
+		return helper.TaxBreakdown{}, err
+	}
+
+	rates := make([]float64, len(lines))
+	for i, line := range lines {
+		lines[i].TaxClassID = classes[line.ProductID].classID
+		lines[i].TaxRate = classes[line.ProductID].rate
+		rates[i] = lines[i].TaxRate
+	}
+	rules := taxRules()
+	taxes := helper.CalculateTax(breakdown.LineNets, rates, rules)
+	for i := range lines {
+		lines[i].Tax = taxes.LineTaxes[i]
+		lines[i].NetTotal = breakdown.LineNets[i]
+		if !rules.Inclusive {
+			lines[i].NetTotal += lines[i].Tax
+		}
+	}
+	return taxes, nil
+}
+
+func AddTaxClass(c *gin.Context) {
+	var taxClass model.TaxClassRequest
+	if err := c.ShouldBindJSON(&taxClass); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	var lastInsertedID int
+	err := config.DB.QueryRow("INSERT INTO tax_classes (name, rate) VALUES ($1, $2) RETURNING id", taxClass.Name, *taxClass.Rate).Scan(&lastInsertedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Tax Class"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Tax class added successfully",
+		"data":    gin.H{"id": strconv.Itoa(lastInsertedID)},
+	})
+}
+
+func GetAllTaxClass(c *gin.Context) {
+	rows, err := config.DB.Query("SELECT id, name, rate, createdAt FROM tax_classes WHERE deletedAt IS NULL ORDER BY name ASC")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Tax Class"})
+		return
+	}
+	defer rows.Close()
+	taxClasses := []model.TaxClassResponse{}
+	for rows.Next() {
+		var tc model.TaxClassResponse
+		if err := rows.Scan(&tc.ID, &tc.Name, &tc.Rate, &tc.CreatedAt); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Tax Class"})
+			return
+		}
+		taxClasses = append(taxClasses, tc)
+	}
+	if err := rows.Err(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Tax Class"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data":    taxClasses,
+	})
+}
+
+// UpdateTaxClass changes a rate for future sales only; past transactions
+// keep the rate they were sold at.
+func UpdateTaxClass(c *gin.Context) {
+	var taxClass model.TaxClassRequest
+	if err := c.ShouldBindJSON(&taxClass); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := config.DB.Exec("UPDATE tax_classes SET name = $1, rate = $2, updatedAt = NOW() WHERE id = $3 AND deletedAt IS NULL",
+		taxClass.Name, *taxClass.Rate, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Tax Class"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tax class not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Tax Class"})
+}
+
+func DeleteTaxClass(c *gin.Context) {
+	taxClassID := c.Param("id")
+
+	var exists, inUse bool
+	err := config.DB.QueryRow(`
+    SELECT
+        EXISTS(SELECT 1 FROM tax_classes WHERE id = $1 AND deletedAt IS NULL),
+        EXISTS(SELECT 1 FROM products WHERE taxClassId = $1 AND deletedAt IS NULL)
+            OR EXISTS(SELECT 1 FROM categories WHERE taxClassId = $1 AND deletedAt IS NULL)`, taxClassID).Scan(&exists, &inUse)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check tax class existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tax class not found"})
+		return
+	}
+	if inUse {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tax class is still assigned"})
+		return
+	}
+
+	_, err = config.DB.Exec("UPDATE tax_classes SET deletedAt = NOW() WHERE id = $1 AND deletedAt IS NULL", taxClassID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Tax Class"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Delete Tax Class"})
+}
+
+// bindTaxClassAssign reads the assignment body and checks the tax class
+// exists. It writes the error response itself and returns false on failure.
+func bindTaxClassAssign(c *gin.Context) (model.TaxClassAssignRequest, bool) {
+	var request model.TaxClassAssignRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return request, false
+	}
+	if request.TaxClassID != nil {
+		exists, err := taxClassExists(*request.TaxClassID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check tax class"})
+			return request, false
+		}
+		if !exists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Tax class not found"})
+			return request, false
+		}
+	}
+	return request, true
+}
+
+func SetProductTaxClass(c *gin.Context) {
+	request, ok := bindTaxClassAssign(c)
+	if !ok {
+		return
+	}
+	productID := c.Param("id")
+
+	exists, err := productExists(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Product"})
+		return
+	}
+	defer tx.Rollback()
+	err = withProductAudit(tx, productID, "update", c.GetInt("userId"), func() error {
+		_, err := tx.Exec("UPDATE products SET taxClassId = $1, version = version + 1, updatedAt = NOW() WHERE id = $2 AND deletedAt IS NULL", request.TaxClassID, productID)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Product"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Product"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Product"})
+}
+
+func SetCategoryTaxClass(c *gin.Context) {
+	request, ok := bindTaxClassAssign(c)
+	if !ok {
+		return
+	}
+
+	result, err := config.DB.Exec("UPDATE categories SET taxClassId = $1, updatedAt = NOW() WHERE id = $2 AND deletedAt IS NULL", request.TaxClassID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Category"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Category"})
+}
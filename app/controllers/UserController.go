@@ -1,17 +1,41 @@
 package controller
 
 import (
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"math/big"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
 	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
 	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper/phone"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var phoneErrors = map[error]string{
+	phone.ErrMissingPlus:      "Phone number must start with '+'",
+	phone.ErrInvalidCharacter: "Phone number contains invalid characters",
+	phone.ErrCountryCode:      "Phone number has an unknown country calling code",
+	phone.ErrLength:           "Phone number has an invalid length for its country",
+}
+
+// normalizePhone brings a phone number to E.164 before it is stored or
+// looked up, answering 400 when it is not valid.
+func normalizePhone(c *gin.Context, raw string) (string, bool) {
+	normalized, err := phone.Normalize(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": phoneErrors[err]})
+		return "", false
+	}
+	return normalized, true
+}
+
 func RegisterStaff(c *gin.Context) {
 	var user model.UserRegisterRequest
 	if err := c.ShouldBindJSON(&user); err != nil {
@@ -19,16 +43,11 @@ func RegisterStaff(c *gin.Context) {
 		return
 	}
 
-	// Check if the phone number contains spaces
-	if strings.Contains(user.PhoneNumber, " ") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number cannot contain spaces"})
-		return
-	}
-	// Check if the phone number starts with '+'
-	if !strings.HasPrefix(user.PhoneNumber, "+") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number must start with '+'"})
+	normalized, ok := normalizePhone(c, user.PhoneNumber)
+	if !ok {
 		return
 	}
+	user.PhoneNumber = normalized
 
 	hashedPassword, err := helper.GeneratePassword(user.Password)
 	if err != nil {
@@ -84,16 +103,11 @@ func RegisterCustomer(c *gin.Context) {
 		return
 	}
 
-	// Check if the phone number contains spaces
-	if strings.Contains(user.PhoneNumber, " ") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number cannot contain spaces"})
-		return
-	}
-	// Check if the phone number starts with '+'
-	if !strings.HasPrefix(user.PhoneNumber, "+") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number must start with '+'"})
+	normalized, ok := normalizePhone(c, user.PhoneNumber)
+	if !ok {
 		return
 	}
+	user.PhoneNumber = normalized
 
 	hashedPassword, err := helper.GeneratePassword(user.Password)
 	if err != nil {
@@ -147,25 +161,26 @@ func Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
-	// Check if the phone number contains spaces
-	if strings.Contains(user.PhoneNumber, " ") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number cannot contain spaces"})
-		return
-	}
-	// Check if the phone number starts with '+'
-	if !strings.HasPrefix(user.PhoneNumber, "+") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Phone number must start with '+'"})
+	normalized, ok := normalizePhone(c, user.PhoneNumber)
+	if !ok {
 		return
 	}
+	user.PhoneNumber = normalized
 
 	var userID int
 	var hashedPassword, phoneNumber, name string
 
-	err := config.DB.QueryRow("SELECT id, password, phoneNumber, name FROM users WHERE phoneNumber = $1", user.PhoneNumber).Scan(&userID, &hashedPassword, &phoneNumber, &name)
+	err := config.DB.QueryRow("SELECT id, COALESCE(password, ''), phoneNumber, name FROM users WHERE phoneNumber = $1", user.PhoneNumber).Scan(&userID, &hashedPassword, &phoneNumber, &name)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
+	// Customers registered by staff have no password until they claim
+	// their account
+	if hashedPassword == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account has not been claimed yet"})
+		return
+	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(user.Password))
 	if err != nil {
@@ -190,6 +205,217 @@ func Login(c *gin.Context) {
 
 }
 
+// CreateCustomer lets staff register a walk-in customer by phone number and
+// name only. The customer can set a password later by claiming the account
+// with a one-time code.
+func CreateCustomer(c *gin.Context) {
+	var user model.CustomerCreateRequest
+	if err := c.ShouldBindJSON(&user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	normalized, ok := normalizePhone(c, user.PhoneNumber)
+	if !ok {
+		return
+	}
+	user.PhoneNumber = normalized
+
+	// Check if the phone number already exists
+	var count int
+	err := config.DB.QueryRow("SELECT COUNT(*) FROM users WHERE phoneNumber = $1", user.PhoneNumber).Scan(&count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error checking phone number"})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Phone number already exists"})
+		return
+	}
+
+	var lastInsertedID int
+	err = config.DB.QueryRow("INSERT INTO users (phoneNumber, name, role) VALUES ($1, $2, 1) RETURNING id",
+		user.PhoneNumber, user.Name).Scan(&lastInsertedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error registering user"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Customer registered successfully",
+		"data": model.CustomerCreateResponse{
+			UserId:      strconv.Itoa(lastInsertedID),
+			PhoneNumber: user.PhoneNumber,
+			Name:        user.Name,
+		},
+	})
+}
+
+func generateClaimCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// IssueClaimCode gives staff a one-time code to hand to a customer who has
+// no password yet. The code is valid for CLAIM_CODE_TTL_MINUTES and
+// replaces any code issued before.
+func IssueClaimCode(c *gin.Context) {
+	customerID := c.Param("id")
+	if _, err := strconv.Atoi(customerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Claim Code"})
+		return
+	}
+	defer tx.Rollback()
+
+	var claimed bool
+	err = tx.QueryRow("SELECT password IS NOT NULL FROM users WHERE id = $1 AND role = 1 AND deletedAt IS NULL FOR UPDATE", customerID).Scan(&claimed)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Claim Code"})
+		return
+	}
+	if claimed {
+		c.JSON(http.StatusConflict, gin.H{"error": "Customer account is already claimed"})
+		return
+	}
+
+	code, err := generateClaimCode()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Claim Code"})
+		return
+	}
+	codeHash, err := helper.GeneratePassword(code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Claim Code"})
+		return
+	}
+	if _, err := tx.Exec("DELETE FROM customer_claim_codes WHERE customerId = $1 AND usedAt IS NULL", customerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Claim Code"})
+		return
+	}
+	var expiresAt time.Time
+	err = tx.QueryRow(`
+    INSERT INTO customer_claim_codes (customerId, codeHash, expiresAt, createdBy)
+    VALUES ($1, $2, NOW() + make_interval(mins => $3), $4)
+    RETURNING expiresAt`, customerID, codeHash, config.GetEnvInt("CLAIM_CODE_TTL_MINUTES", 15), c.GetInt("userId")).Scan(&expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Claim Code"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Issue Claim Code"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Claim code issued successfully",
+		"data": model.ClaimCodeResponse{
+			Code:      code,
+			ExpiresAt: expiresAt,
+		},
+	})
+}
+
+// ClaimCustomerAccount sets the password of a customer registered by staff
+// and logs them in. Every failure gets the same answer so the endpoint
+// does not tell which phone numbers are registered; a code stops working
+// after CLAIM_CODE_MAX_ATTEMPTS wrong guesses.
+func ClaimCustomerAccount(c *gin.Context) {
+	var request model.ClaimAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	normalized, ok := normalizePhone(c, request.PhoneNumber)
+	if !ok {
+		return
+	}
+	request.PhoneNumber = normalized
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+		return
+	}
+	defer tx.Rollback()
+
+	var userID, codeID, attempts int
+	var name, codeHash string
+	err = tx.QueryRow(`
+    SELECT u.id, u.name, cc.id, cc.codeHash, cc.attempts
+    FROM users u
+    JOIN customer_claim_codes cc ON cc.customerId = u.id
+    WHERE u.phoneNumber = $1 AND u.role = 1 AND u.deletedAt IS NULL AND u.password IS NULL
+        AND cc.usedAt IS NULL AND cc.expiresAt > NOW()
+    ORDER BY cc.id DESC
+    LIMIT 1
+    FOR UPDATE`, request.PhoneNumber).Scan(&userID, &name, &codeID, &codeHash, &attempts)
+	if err == sql.ErrNoRows || (err == nil && attempts >= config.GetEnvInt("CLAIM_CODE_MAX_ATTEMPTS", 5)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired code"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+		return
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(codeHash), []byte(request.Code)) != nil {
+		if _, err := tx.Exec("UPDATE customer_claim_codes SET attempts = attempts + 1 WHERE id = $1", codeID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired code"})
+		return
+	}
+
+	hashedPassword, err := helper.GeneratePassword(request.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+		return
+	}
+	if _, err := tx.Exec("UPDATE users SET password = $1, updatedAt = NOW() WHERE id = $2", hashedPassword, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+		return
+	}
+	if _, err := tx.Exec("UPDATE customer_claim_codes SET usedAt = NOW() WHERE id = $1", codeID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Claim Account"})
+		return
+	}
+
+	token, err := helper.GenerateJWT(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error generating token"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account claimed successfully",
+		"data": model.UserRegisterResponse{
+			UserId:      strconv.Itoa(userID),
+			PhoneNumber: request.PhoneNumber,
+			Name:        name,
+			AccessToken: token,
+		},
+	})
+}
+
 func GetUsers(c *gin.Context) {
 	var params model.GetCustomerParams
 	if err := c.BindQuery(&params); err != nil {
@@ -0,0 +1,384 @@
+package controller
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+)
+
+// flatProductSource replaces the products table when listing with
+// variants=flat: every variant becomes its own row carrying the parent's
+// descriptive fields, while products without variants appear unchanged.
+// Column names match products so the usual filters keep working.
+var flatProductSource = `(
+    SELECT
+        pr.id, pr.name, COALESCE(v.sku, pr.sku) AS sku, pr.categoryId, pr.taxClassId, pr.imageUrl, pr.notes,
+        COALESCE(v.price, pr.price) AS price, COALESCE(v.stock, pr.stock) AS stock, pr.location,
+        pr.isAvailable AND COALESCE(v.isAvailable, true) AS isAvailable, pr.type, pr.version, pr.createdAt, pr.deletedAt,
+        CASE WHEN v.id IS NULL THEN pr.available
+            ELSE GREATEST(v.stock - ` + reservedStock("variantId", "v.id") + `, 0) END AS available,
+        v.id AS variantId, v.options AS variantOptions
+    FROM ` + productSource + ` pr
+    LEFT JOIN product_variants v ON v.productId = pr.id AND v.deletedAt IS NULL
+)`
+
+func productExists(productID string) (bool, error) {
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deletedAt IS NULL)", productID).Scan(&exists)
+	return exists, err
+}
+
+func getProductOptions(productIDs []string) (map[string][]model.ProductOption, error) {
+	options := map[string][]model.ProductOption{}
+	rows, err := config.DB.Query("SELECT productId, name, optionValues FROM product_options WHERE productId = ANY($1::int[]) ORDER BY position ASC", pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var productID string
+		var option model.ProductOption
+		if err := rows.Scan(&productID, &option.Name, pq.Array(&option.Values)); err != nil {
+			return nil, err
+		}
+		options[productID] = append(options[productID], option)
+	}
+	return options, rows.Err()
+}
+
+func getProductVariants(productIDs []string) (map[string][]model.VariantResponse, error) {
+	variants := map[string][]model.VariantResponse{}
+	rows, err := config.DB.Query(`
+    SELECT v.id, v.productId, v.sku, v.options, v.price, COALESCE(v.price, p.price), v.stock,
+        GREATEST(v.stock - `+reservedStock("variantId", "v.id")+`, 0), v.isAvailable, v.createdAt
+    FROM product_variants v
+    JOIN `+productSource+` p ON p.id = v.productId
+    WHERE v.productId = ANY($1::int[]) AND v.deletedAt IS NULL
+    ORDER BY v.id ASC`, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v model.VariantResponse
+		var options []byte
+		if err := rows.Scan(&v.ID, &v.ProductID, &v.SKU, &options, &v.PriceOverride, &v.Price, &v.Stock, &v.Available, &v.IsAvailable, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(options, &v.Options); err != nil {
+			return nil, err
+		}
+		variants[v.ProductID] = append(variants[v.ProductID], v)
+	}
+	return variants, rows.Err()
+}
+
+// attachVariants fills Options and Variants of every product for the
+// variants=nested listing.
+func attachVariants(products []model.ProductResponse) error {
+	if len(products) == 0 {
+		return nil
+	}
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	options, err := getProductOptions(ids)
+	if err != nil {
+		return err
+	}
+	variants, err := getProductVariants(ids)
+	if err != nil {
+		return err
+	}
+	for i := range products {
+		products[i].Options = options[products[i].ID]
+		products[i].Variants = variants[products[i].ID]
+	}
+	return nil
+}
+
+// validateVariantOptions checks that a variant picks exactly one allowed
+// value for every option defined on its product.
+func validateVariantOptions(defined []model.ProductOption, picked map[string]string) bool {
+	if len(defined) == 0 || len(defined) != len(picked) {
+		return false
+	}
+	for _, option := range defined {
+		value, ok := picked[option.Name]
+		if !ok {
+			return false
+		}
+		allowed := false
+		for _, v := range option.Values {
+			if v == value {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+func variantSKUTaken(sku string, excludeVariantID string) (bool, error) {
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM product_variants WHERE sku = $1 AND deletedAt IS NULL AND id::text <> $2)", sku, excludeVariantID).Scan(&exists)
+	return exists, err
+}
+
+func SetProductOptions(c *gin.Context) {
+	var request model.ProductOptionsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	productID := c.Param("id")
+
+	exists, err := productExists(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, option := range request.Options {
+		if seen[option.Name] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Duplicate option name"})
+			return
+		}
+		seen[option.Name] = true
+	}
+
+	// Existing variants must still be valid under the new definitions
+	variants, err := getProductVariants([]string{productID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product variants"})
+		return
+	}
+	for _, v := range variants[productID] {
+		if !validateVariantOptions(request.Options, v.Options) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Options do not match existing variant " + v.SKU})
+			return
+		}
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Set Options"})
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM product_options WHERE productId = $1", productID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Set Options"})
+		return
+	}
+	for i, option := range request.Options {
+		_, err := tx.Exec("INSERT INTO product_options (productId, name, optionValues, position) VALUES ($1, $2, $3, $4)",
+			productID, option.Name, pq.Array(option.Values), i)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Set Options"})
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Set Options"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Set Product Options"})
+}
+
+func GetProductVariants(c *gin.Context) {
+	productID := c.Param("id")
+
+	exists, err := productExists(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	options, err := getProductOptions([]string{productID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Variants"})
+		return
+	}
+	variants, err := getProductVariants([]string{productID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Retrieve Variants"})
+		return
+	}
+	if options[productID] == nil {
+		options[productID] = []model.ProductOption{}
+	}
+	if variants[productID] == nil {
+		variants[productID] = []model.VariantResponse{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Success",
+		"data": gin.H{
+			"options":  options[productID],
+			"variants": variants[productID],
+		},
+	})
+}
+
+func AddProductVariant(c *gin.Context) {
+	var variant model.VariantRequest
+	if err := c.ShouldBindJSON(&variant); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	productID := c.Param("id")
+
+	exists, err := productExists(productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	options, err := getProductOptions([]string{productID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product options"})
+		return
+	}
+	if !validateVariantOptions(options[productID], variant.Options) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Variant options do not match product options"})
+		return
+	}
+
+	taken, err := variantSKUTaken(variant.SKU, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check variant SKU"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Variant SKU already exists"})
+		return
+	}
+
+	optionsJSON, _ := json.Marshal(variant.Options)
+	var lastInsertedID int
+	err = config.DB.QueryRow("INSERT INTO product_variants (productId, sku, options, price, stock, isAvailable) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		productID, variant.SKU, string(optionsJSON), variant.Price, *variant.Stock, *variant.IsAvailable).Scan(&lastInsertedID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Add Variant"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Variant added successfully",
+		"data":    gin.H{"id": strconv.Itoa(lastInsertedID)},
+	})
+}
+
+func UpdateProductVariant(c *gin.Context) {
+	var variant model.VariantRequest
+	if err := c.ShouldBindJSON(&variant); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	productID := c.Param("id")
+	variantID := c.Param("variantId")
+
+	var exists bool
+	err := config.DB.QueryRow("SELECT EXISTS(SELECT 1 FROM product_variants WHERE id = $1 AND productId = $2 AND deletedAt IS NULL)", variantID, productID).Scan(&exists)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check variant existence"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+
+	options, err := getProductOptions([]string{productID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check product options"})
+		return
+	}
+	if !validateVariantOptions(options[productID], variant.Options) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Variant options do not match product options"})
+		return
+	}
+
+	taken, err := variantSKUTaken(variant.SKU, variantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check variant SKU"})
+		return
+	}
+	if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Variant SKU already exists"})
+		return
+	}
+
+	optionsJSON, _ := json.Marshal(variant.Options)
+	query := `
+    UPDATE product_variants
+    SET
+        sku = $1,
+        options = $2,
+        price = $3,
+        stock = $4,
+        isAvailable = $5,
+        updatedAt = NOW()
+    WHERE
+        id = $6
+        AND deletedAt IS NULL
+`
+	_, err = config.DB.Exec(query, variant.SKU, string(optionsJSON), variant.Price, *variant.Stock, *variant.IsAvailable, variantID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Update Variant"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Update Variant"})
+}
+
+func DeleteProductVariant(c *gin.Context) {
+	productID := c.Param("id")
+	variantID := c.Param("variantId")
+
+	result, err := config.DB.Exec("UPDATE product_variants SET deletedAt = NOW() WHERE id = $1 AND productId = $2 AND deletedAt IS NULL", variantID, productID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Delete Variant"})
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Variant not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully Delete Variant"})
+}
+
+func scanFlatVariant(rows *sql.Rows) (model.ProductResponse, error) {
+	var variantID sql.NullString
+	var options []byte
+	p, err := scanProduct(rows, &variantID, &options)
+	if err != nil {
+		return p, err
+	}
+	p.VariantID = variantID.String
+	if options != nil {
+		err = json.Unmarshal(options, &p.VariantOptions)
+	}
+	return p, err
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	model "github.com/Project-Sprint-Golang/EniQilo-Store/app/models"
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+// soldComponents loads the bundle components of the lines of a
+// transaction as they were at the time of sale, by transaction detail.
+func soldComponents(tx *sql.Tx, transactionID string) (map[int][]bundleComponent, error) {
+	rows, err := tx.Query(`
+    SELECT tdc.transactionDetailId, tdc.productId, tdc.quantity
+    FROM transaction_detail_components tdc
+    JOIN transaction_details td ON td.id = tdc.transactionDetailId
+    WHERE td.transactionId = $1
+    ORDER BY tdc.id ASC`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	components := map[int][]bundleComponent{}
+	for rows.Next() {
+		var detailID int
+		var component bundleComponent
+		if err := rows.Scan(&detailID, &component.ProductID, &component.Quantity); err != nil {
+			return nil, err
+		}
+		components[detailID] = append(components[detailID], component)
+	}
+	return components, rows.Err()
+}
+
+// soldLines loads the lines of a transaction as checkout lines, enough to
+// move their stock back.
+func soldLines(tx *sql.Tx, transactionID string) ([]checkoutLine, error) {
+	components, err := soldComponents(tx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(`
+    SELECT td.id, td.productId, COALESCE(td.variantId::text, ''), p.type, td.quantity
+    FROM transaction_details td
+    JOIN products p ON p.id = td.productId
+    WHERE td.transactionId = $1
+    ORDER BY td.id ASC`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var lines []checkoutLine
+	for rows.Next() {
+		var detailID int
+		var line checkoutLine
+		if err := rows.Scan(&detailID, &line.ProductID, &line.VariantID, &line.Type, &line.Quantity); err != nil {
+			return nil, err
+		}
+		line.Components = components[detailID]
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+// VoidTransaction cancels a sale shortly after it was made: its stock is
+// put back, the promotions it used are released, its loyalty points are
+// undone and what it took from gift cards and store credit is given back.
+// Only sales younger than VOID_WINDOW_MINUTES that have not been refunded
+// can be voided; anything else goes through a refund.
+func VoidTransaction(c *gin.Context) {
+	var request model.VoidTransactionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	transactionID := c.Param("id")
+	if _, err := strconv.Atoi(transactionID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	tx, err := config.DB.Begin()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	defer tx.Rollback()
+
+	windowMinutes := config.GetEnvInt("VOID_WINDOW_MINUTES", 15)
+	var customerID, refundStatus string
+	var voided, withinWindow bool
+	err = tx.QueryRow(`
+    SELECT customerId, refundStatus, voidedAt IS NOT NULL, createdAt > NOW() - make_interval(mins => $2)
+    FROM transactions WHERE id = $1 FOR UPDATE`, transactionID, windowMinutes).Scan(&customerID, &refundStatus, &voided, &withinWindow)
+	if err == sql.ErrNoRows {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	if voided {
+		c.JSON(http.StatusConflict, gin.H{"error": "Transaction is already voided"})
+		return
+	}
+	if refundStatus != "none" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Refunded transactions cannot be voided"})
+		return
+	}
+	if !withinWindow {
+		c.JSON(http.StatusConflict, gin.H{"error": "Void window has passed"})
+		return
+	}
+
+	lines, err := soldLines(tx, transactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	if err := restockLines(tx, lines); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	_, err = tx.Exec(`
+    UPDATE promotions SET usageCount = usageCount - 1
+    WHERE usageCount > 0 AND id IN (SELECT promotionId FROM transaction_discounts WHERE transactionId = $1)`, transactionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	if err := reverseVoidPoints(tx, transactionID, customerID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	if err := reverseVoidGiftCards(tx, transactionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+
+	var voidedAt time.Time
+	err = tx.QueryRow("UPDATE transactions SET voidedAt = NOW(), voidedBy = $1, voidReason = $2 WHERE id = $3 RETURNING voidedAt",
+		c.GetInt("userId"), request.Reason, transactionID).Scan(&voidedAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Void Transaction"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Successfully Void Transaction",
+		"data": gin.H{
+			"transactionId": transactionID,
+			"voidedAt":      voidedAt,
+			"voidReason":    request.Reason,
+		},
+	})
+}
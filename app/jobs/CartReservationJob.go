@@ -0,0 +1,61 @@
+package job
+
+import (
+	"log"
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+)
+
+const reservationSweepInterval = time.Minute
+
+// StartReservationSweeper periodically releases the stock reservations of
+// carts that have expired. Expired reservations are already ignored when
+// stock is counted; sweeping keeps the table small and marks the carts.
+func StartReservationSweeper() {
+	go func() {
+		ticker := time.NewTicker(reservationSweepInterval)
+		defer ticker.Stop()
+		for {
+			released, err := ReleaseExpiredReservations()
+			if err != nil {
+				log.Println("release expired reservations:", err)
+			} else if released > 0 {
+				log.Printf("released reservations of %d expired carts", released)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func ReleaseExpiredReservations() (int, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+    UPDATE carts SET status = 'expired', updatedAt = NOW()
+    WHERE id IN (
+        SELECT id FROM carts WHERE status = 'open' AND expiresAt <= NOW()
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id`)
+	if err != nil {
+		return 0, err
+	}
+	expired := 0
+	for rows.Next() {
+		expired++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.Exec("DELETE FROM stock_reservations WHERE expiresAt <= NOW()"); err != nil {
+		return 0, err
+	}
+	return expired, tx.Commit()
+}
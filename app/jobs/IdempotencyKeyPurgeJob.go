@@ -0,0 +1,36 @@
+package job
+
+import (
+	"log"
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+)
+
+const idempotencyKeyPurgeInterval = time.Hour
+
+// StartIdempotencyKeyPurge periodically deletes stored responses whose
+// retention window has passed.
+func StartIdempotencyKeyPurge() {
+	go func() {
+		ticker := time.NewTicker(idempotencyKeyPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purged, err := PurgeExpiredIdempotencyKeys()
+			if err != nil {
+				log.Println("purge expired idempotency keys:", err)
+			} else if purged > 0 {
+				log.Printf("purged %d expired idempotency keys", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func PurgeExpiredIdempotencyKeys() (int64, error) {
+	result, err := config.DB.Exec("DELETE FROM idempotency_keys WHERE expiresAt <= NOW()")
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
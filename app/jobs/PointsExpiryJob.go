@@ -0,0 +1,47 @@
+package job
+
+import (
+	"log"
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+)
+
+const pointsExpiryInterval = time.Hour
+
+// StartPointsExpiry periodically writes off loyalty points whose expiry
+// date has passed.
+func StartPointsExpiry() {
+	go func() {
+		ticker := time.NewTicker(pointsExpiryInterval)
+		defer ticker.Stop()
+		for {
+			customers, err := ExpirePoints()
+			if err != nil {
+				log.Println("expire loyalty points:", err)
+			} else if customers > 0 {
+				log.Printf("expired loyalty points of %d customers", customers)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// ExpirePoints zeroes the unused part of every expired credit and records
+// one expire movement per customer, so the ledger keeps adding up to the
+// balance. It returns the number of customers whose points expired.
+func ExpirePoints() (int64, error) {
+	result, err := config.DB.Exec(`
+    WITH expired AS (
+        UPDATE points_ledger p SET remaining = 0
+        FROM (SELECT id, remaining FROM points_ledger WHERE remaining > 0 AND expiresAt <= NOW() FOR UPDATE) lots
+        WHERE p.id = lots.id
+        RETURNING p.customerId, lots.remaining
+    )
+    INSERT INTO points_ledger (customerId, type, points)
+    SELECT customerId, 'expire', -SUM(remaining) FROM expired GROUP BY customerId`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,84 @@
+package job
+
+import (
+	"log"
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/lib/pq"
+)
+
+const productPurgeInterval = time.Hour
+
+// StartProductPurge periodically hard-deletes products that have been in the
+// trash longer than PRODUCT_RETENTION_DAYS. Products still referenced by a
+// transaction or a cart, used as a bundle component or targeted by a
+// promotion are kept.
+func StartProductPurge() {
+	retentionDays := config.GetEnvInt("PRODUCT_RETENTION_DAYS", 30)
+	go func() {
+		ticker := time.NewTicker(productPurgeInterval)
+		defer ticker.Stop()
+		for {
+			purged, err := PurgeDeletedProducts(retentionDays)
+			if err != nil {
+				log.Println("purge deleted products:", err)
+			} else if purged > 0 {
+				log.Printf("purged %d deleted products", purged)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+func PurgeDeletedProducts(retentionDays int) (int, error) {
+	tx, err := config.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+    SELECT p.id FROM products p
+    WHERE p.deletedAt < NOW() - make_interval(days => $1)
+        AND NOT EXISTS (SELECT 1 FROM transaction_details td WHERE td.productId = p.id)
+        AND NOT EXISTS (SELECT 1 FROM bundle_items bi WHERE bi.productId = p.id)
+        AND NOT EXISTS (SELECT 1 FROM promotions pm WHERE pm.productId = p.id)
+        AND NOT EXISTS (SELECT 1 FROM cart_items ci WHERE ci.productId = p.id)
+    FOR UPDATE SKIP LOCKED`, retentionDays)
+	if err != nil {
+		return 0, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Rows that reference the product go first
+	for _, query := range []string{
+		"DELETE FROM product_options WHERE productId = ANY($1)",
+		"DELETE FROM product_prices WHERE productId = ANY($1)",
+		"DELETE FROM product_currency_prices WHERE productId = ANY($1)",
+		"DELETE FROM stock_reservations WHERE productId = ANY($1) OR variantId IN (SELECT id FROM product_variants WHERE productId = ANY($1))",
+		"DELETE FROM product_variants WHERE productId = ANY($1)",
+		"DELETE FROM bundle_items WHERE bundleId = ANY($1)",
+		"DELETE FROM products WHERE id = ANY($1)",
+	} {
+		if _, err := tx.Exec(query, pq.Array(ids)); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), tx.Commit()
+}
@@ -0,0 +1,166 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+// responseRecorder keeps a copy of the response body while writing it.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// requestHash fingerprints what a request asks for, so a key reused for a
+// different request can be told apart from a retry.
+func requestHash(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// IdempotencyMiddleware makes a handler safe to retry. The first response
+// to a request carrying an Idempotency-Key header is stored for
+// IDEMPOTENCY_KEY_RETENTION_HOURS and replayed for retries with the same
+// key instead of running the handler again. Reusing a key for a different
+// request is rejected with 422. Server errors and panics are not stored, so
+// a request that failed can be retried with the same key; so is a response
+// that could not be stored. Bodies over IDEMPOTENCY_MAX_BODY_BYTES are
+// rejected with 413. Must run after AuthMiddleware; keys are scoped to the
+// user.
+func IdempotencyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("Idempotency-Key")
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 255 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			c.Abort()
+			return
+		}
+
+		maxBytes := int64(config.GetEnvInt("IDEMPOTENCY_MAX_BODY_BYTES", 1<<20))
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBytes+1))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			c.Abort()
+			return
+		}
+		if int64(len(body)) > maxBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		hash := requestHash(c, body)
+		userID := c.GetInt("userId")
+
+		// An expired key is free to be used again
+		if _, err := config.DB.Exec("DELETE FROM idempotency_keys WHERE userId = $1 AND idempotencyKey = $2 AND expiresAt <= NOW()", userID, key); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Check Idempotency Key"})
+			c.Abort()
+			return
+		}
+		retentionHours := config.GetEnvInt("IDEMPOTENCY_KEY_RETENTION_HOURS", 24)
+		var id int
+		err = config.DB.QueryRow(`
+    INSERT INTO idempotency_keys (userId, idempotencyKey, requestHash, expiresAt)
+    VALUES ($1, $2, $3, NOW() + make_interval(hours => $4))
+    ON CONFLICT (userId, idempotencyKey) DO NOTHING
+    RETURNING id`, userID, key, hash, retentionHours).Scan(&id)
+		if err == sql.ErrNoRows {
+			replayResponse(c, userID, key, hash)
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Check Idempotency Key"})
+			c.Abort()
+			return
+		}
+
+		// Unless the response gets stored, the key is released again, also
+		// when the handler panics, so a retry is not locked out until the
+		// key expires
+		stored := false
+		defer func() {
+			if stored {
+				return
+			}
+			if _, err := config.DB.Exec("DELETE FROM idempotency_keys WHERE id = $1", id); err != nil {
+				log.Println("release idempotency key:", err)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		result, err := config.DB.Exec("UPDATE idempotency_keys SET status = 'completed', responseStatus = $1, responseContentType = $2, responseBody = $3 WHERE id = $4 AND status = 'processing'",
+			status, recorder.Header().Get("Content-Type"), recorder.body.Bytes(), id)
+		if err != nil {
+			log.Println("store idempotent response:", err)
+			return
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			log.Println("store idempotent response: key", id, "is gone")
+			return
+		}
+		stored = true
+	}
+}
+
+// replayResponse answers a request whose key has been seen before.
+func replayResponse(c *gin.Context, userID int, key string, hash string) {
+	defer c.Abort()
+
+	var storedHash, status, contentType string
+	var responseStatus sql.NullInt64
+	var body []byte
+	err := config.DB.QueryRow(`
+    SELECT requestHash, status, responseStatus, COALESCE(responseContentType, ''), responseBody
+    FROM idempotency_keys WHERE userId = $1 AND idempotencyKey = $2`, userID, key).Scan(&storedHash, &status, &responseStatus, &contentType, &body)
+	if err == sql.ErrNoRows {
+		// The first request failed and released the key in the meantime
+		c.JSON(http.StatusConflict, gin.H{"error": "Request with this Idempotency-Key is still being processed"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error when Check Idempotency Key"})
+		return
+	}
+	if storedHash != hash {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was used for a different request"})
+		return
+	}
+	if status != "completed" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Request with this Idempotency-Key is still being processed"})
+		return
+	}
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(int(responseStatus.Int64), contentType, body)
+}
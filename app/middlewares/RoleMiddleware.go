@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RoleCustomer   = 1
+	RoleStaff      = 2
+	RoleSupervisor = 3
+)
+
+// RoleMiddleware lets through only users with one of the given roles. It
+// must run after AuthMiddleware. The role is read from the database rather
+// than the token, so a role change takes effect immediately.
+func RoleMiddleware(roles ...int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var role int
+		err := config.DB.QueryRow("SELECT role FROM users WHERE id = $1 AND deletedAt IS NULL", c.GetInt("userId")).Scan(&role)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+			c.Abort()
+			return
+		}
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		c.Abort()
+	}
+}
@@ -0,0 +1,29 @@
+package model
+
+import "github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+
+type BundleItemRequest struct {
+	ProductID string `json:"productId" binding:"required,numeric"`
+	Quantity  int    `json:"quantity" binding:"required,min=1,max=1000"`
+}
+
+type BundleRequest struct {
+	Name        string              `json:"name" binding:"required,min=1,max=30"`
+	SKU         string              `json:"sku" binding:"required,min=1,max=30"`
+	CategoryID  string              `json:"categoryId" binding:"required,numeric"`
+	ImageURL    string              `json:"imageUrl" binding:"required,url"`
+	Notes       string              `json:"notes" binding:"required,min=1,max=200"`
+	Location    string              `json:"location" binding:"required,min=1,max=200"`
+	IsAvailable *bool               `json:"isAvailable" binding:"required"`
+	Pricing     string              `json:"pricing" binding:"required,oneof=fixed computed"`
+	Price       helper.Money        `json:"price" binding:"required_if=Pricing fixed,omitempty,min=100"`
+	Discount    float64             `json:"discount" binding:"min=0,max=100"`
+	Items       []BundleItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type BundleItemResponse struct {
+	ProductID string `json:"productId"`
+	Name      string `json:"name"`
+	SKU       string `json:"sku"`
+	Quantity  int    `json:"quantity"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type CartRequest struct {
+	CustomerID     string                  `json:"customerId" binding:"omitempty,numeric"`
+	ProductDetails []CheckoutProductDetail `json:"productDetails" binding:"omitempty,dive"`
+}
+
+type CartItemRequest struct {
+	ProductID string `json:"productId" binding:"required,numeric"`
+	VariantID string `json:"variantId" binding:"omitempty,numeric"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+type CartItemUpdateRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+type CartItemResponse struct {
+	ID        string `json:"id"`
+	ProductID string `json:"productId"`
+	VariantID string `json:"variantId,omitempty"`
+	Quantity  int    `json:"quantity"`
+}
+
+type CartResponse struct {
+	ID         string `json:"id"`
+	StaffID    string `json:"staffId"`
+	CustomerID string `json:"customerId,omitempty"`
+
+	// open, checked_out, expired or cancelled
+	Status string `json:"status"`
+
+	Items         []CartItemResponse `json:"items"`
+	TransactionID string             `json:"transactionId,omitempty"`
+	ExpiresAt     time.Time          `json:"expiresAt"`
+	CreatedAt     time.Time          `json:"createdAt"`
+	UpdatedAt     time.Time          `json:"updatedAt"`
+}
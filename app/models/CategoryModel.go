@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+type CategoryRequest struct {
+	Name         string  `json:"name" binding:"required,min=1,max=50"`
+	ParentID     *string `json:"parentId" binding:"omitempty,numeric"`
+	DisplayOrder int     `json:"displayOrder" binding:"min=0"`
+	IsActive     *bool   `json:"isActive" binding:"required"`
+}
+
+type CategoryResponse struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	ParentID     *string            `json:"parentId"`
+	TaxClassID   *string            `json:"taxClassId"`
+	DisplayOrder int                `json:"displayOrder"`
+	IsActive     bool               `json:"isActive"`
+	CreatedAt    time.Time          `json:"createdAt"`
+	Children     []CategoryResponse `json:"children,omitempty"`
+}
+
+type GetCategoryParams struct {
+	ID       string `form:"id"`
+	Name     string `form:"name"`
+	ParentID string `form:"parentId"`
+	IsActive string `form:"isActive"`
+	Tree     string `form:"tree"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+type ExchangeRateRequest struct {
+	Currency      string      `json:"currency" binding:"required,iso4217"`
+	Rate          json.Number `json:"rate" binding:"required"`
+	EffectiveFrom *time.Time  `json:"effectiveFrom"`
+}
+
+type ExchangeRateResponse struct {
+	ID            string      `json:"id"`
+	Currency      string      `json:"currency"`
+	Rate          json.Number `json:"rate"`
+	EffectiveFrom time.Time   `json:"effectiveFrom"`
+	Status        string      `json:"status"`
+	CreatedAt     time.Time   `json:"createdAt"`
+}
+
+type GetExchangeRateParams struct {
+	Currency string `form:"currency"`
+	Limit    int    `form:"limit,default=5"`
+	Offset   int    `form:"offset,default=0"`
+}
+
+type CurrencyPriceRequest struct {
+	Price helper.Money `json:"price" binding:"required,min=100"`
+}
+
+type CurrencyPriceResponse struct {
+	ProductID string       `json:"productId"`
+	Currency  string       `json:"currency"`
+	Price     helper.Money `json:"price"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
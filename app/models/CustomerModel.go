@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+// CustomerProfileRequest replaces a customer's name and profile. Optional
+// fields left empty are cleared.
+type CustomerProfileRequest struct {
+	Name             string `json:"name" binding:"required,min=5,max=50"`
+	Email            string `json:"email" binding:"omitempty,email,max=100"`
+	Birthday         string `json:"birthday" binding:"omitempty,datetime=2006-01-02"`
+	Address          string `json:"address" binding:"max=255"`
+	MarketingConsent bool   `json:"marketingConsent"`
+}
+
+// CustomerStats sums up a customer's purchases. Voided sales are left out
+// and refunds are taken off the lifetime value, which is in the base
+// currency.
+type CustomerStats struct {
+	VisitCount    int          `json:"visitCount"`
+	LifetimeValue helper.Money `json:"lifetimeValue"`
+	AverageBasket helper.Money `json:"averageBasket"`
+	FirstVisitAt  *time.Time   `json:"firstVisitAt,omitempty"`
+	LastVisitAt   *time.Time   `json:"lastVisitAt,omitempty"`
+}
+
+type CustomerProfileResponse struct {
+	UserId             string        `json:"userId"`
+	PhoneNumber        string        `json:"phoneNumber"`
+	Name               string        `json:"name"`
+	Email              string        `json:"email,omitempty"`
+	Birthday           string        `json:"birthday,omitempty"`
+	Address            string        `json:"address,omitempty"`
+	MarketingConsent   bool          `json:"marketingConsent"`
+	MarketingConsentAt *time.Time    `json:"marketingConsentAt,omitempty"`
+	Stats              CustomerStats `json:"stats"`
+	CreatedAt          time.Time     `json:"createdAt"`
+}
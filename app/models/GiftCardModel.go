@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+// GiftCardRequest issues a gift card. Without a code one is generated;
+// without expiresAt the card never expires.
+type GiftCardRequest struct {
+	Code           string       `json:"code" binding:"omitempty,min=8,max=32"`
+	InitialBalance helper.Money `json:"initialBalance" binding:"required,min=100"`
+	ExpiresAt      *time.Time   `json:"expiresAt"`
+}
+
+// GiftCardEntry is one change of a gift card or store credit balance.
+// Amount is positive when the balance grew.
+type GiftCardEntry struct {
+	ID            string       `json:"id"`
+	Type          string       `json:"type"`
+	Amount        helper.Money `json:"amount"`
+	Balance       helper.Money `json:"balance"`
+	TransactionID string       `json:"transactionId,omitempty"`
+	RefundID      string       `json:"refundId,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
+
+// GiftCardResponse is a gift card or a customer's store credit. Amounts
+// are in the base currency.
+type GiftCardResponse struct {
+	ID             string          `json:"id"`
+	Code           string          `json:"code,omitempty"`
+	Kind           string          `json:"kind"`
+	CustomerID     string          `json:"customerId,omitempty"`
+	InitialBalance helper.Money    `json:"initialBalance"`
+	Balance        helper.Money    `json:"balance"`
+	ExpiresAt      *time.Time      `json:"expiresAt,omitempty"`
+	History        []GiftCardEntry `json:"history"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type GetPointsParams struct {
+	Limit  int `form:"limit,default=10" binding:"min=0"`
+	Offset int `form:"offset,default=0" binding:"min=0"`
+}
+
+// PointsEntry is one movement of a customer's points: earned or redeemed
+// at checkout, expired, taken back after a refund or void (reverse), or
+// given back after a void (restore).
+type PointsEntry struct {
+	ID            string     `json:"id"`
+	Type          string     `json:"type"`
+	Points        int        `json:"points"`
+	TransactionID string     `json:"transactionId,omitempty"`
+	RefundID      string     `json:"refundId,omitempty"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+type PointsResponse struct {
+	CustomerID string        `json:"customerId"`
+	Balance    int           `json:"balance"`
+	History    []PointsEntry `json:"history"`
+}
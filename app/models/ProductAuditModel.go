@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+type ProductAuditResponse struct {
+	ID        string                 `json:"id"`
+	ProductID string                 `json:"productId"`
+	Action    string                 `json:"action"`
+	ActorID   string                 `json:"actorId"`
+	ActorName string                 `json:"actorName"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+type GetProductHistoryParams struct {
+	Limit  int `form:"limit,default=5"`
+	Offset int `form:"offset,default=0"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+
+type ProductBulkFilter struct {
+	Name        string `json:"name"`
+	IsAvailable string `json:"isAvailable"`
+	Category    string `json:"category"`
+	CategoryID  string `json:"categoryId"`
+	SKU         string `json:"sku"`
+	InStock     string `json:"inStock"`
+}
+
+type ProductBulkUpdate struct {
+	Price              *helper.Money `json:"price" binding:"omitempty,min=100"`
+	PriceChangePercent *float64      `json:"priceChangePercent" binding:"omitempty,min=-99,max=1000"`
+	IsAvailable        *bool         `json:"isAvailable"`
+	CategoryID         *string       `json:"categoryId" binding:"omitempty,numeric"`
+}
+
+type ProductBulkUpdateRequest struct {
+	IDs    []string           `json:"ids" binding:"omitempty,max=1000,dive,numeric"`
+	Filter *ProductBulkFilter `json:"filter"`
+	Update ProductBulkUpdate  `json:"update" binding:"required"`
+}
+
+type ProductBulkDeleteRequest struct {
+	IDs    []string           `json:"ids" binding:"omitempty,max=1000,dive,numeric"`
+	Filter *ProductBulkFilter `json:"filter"`
+}
+
+type ProductBulkOutcome struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
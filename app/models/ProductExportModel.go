@@ -0,0 +1,6 @@
+package model
+
+type ProductExportParams struct {
+	Format  string `form:"format" binding:"required,oneof=csv jsonl"`
+	Columns string `form:"columns"`
+}
@@ -0,0 +1,26 @@
+package model
+
+type ProductImportParams struct {
+	DryRun string `form:"dryRun"`
+	Mode   string `form:"mode,default=insert" binding:"oneof=insert upsert"`
+	Atomic string `form:"atomic,default=true"`
+}
+
+type ProductImportRow struct {
+	Row    int      `json:"row"`
+	SKU    string   `json:"sku"`
+	Status string   `json:"status"`
+	ID     string   `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+type ProductImportResponse struct {
+	DryRun    bool               `json:"dryRun"`
+	Mode      string             `json:"mode"`
+	Atomic    bool               `json:"atomic"`
+	Committed bool               `json:"committed"`
+	Total     int                `json:"total"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Rows      []ProductImportRow `json:"rows"`
+}
@@ -1,31 +1,64 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
 
 type ProductRequest struct {
-	Name        string  `json:"name" binding:"required,min=1,max=30"`
-	SKU         string  `json:"sku" binding:"required,min=1,max=30"`
-	Category    string  `json:"category" binding:"required,oneof=Clothing Accessories Footwear Beverages"`
-	ImageURL    string  `json:"imageUrl" binding:"required,url"`
-	Notes       string  `json:"notes" binding:"required,min=1,max=200"`
-	Price       float64 `json:"price" binding:"required,min=1"`
-	Stock       int     `json:"stock" binding:"required,min=0,max=100000"`
-	Location    string  `json:"location" binding:"required,min=1,max=200"`
-	IsAvailable bool    `json:"isAvailable" binding:"required"`
+	Name        string       `json:"name" binding:"required,min=1,max=30"`
+	SKU         string       `json:"sku" binding:"required,min=1,max=30"`
+	CategoryID  string       `json:"categoryId" binding:"required,numeric"`
+	ImageURL    string       `json:"imageUrl" binding:"required,url"`
+	Notes       string       `json:"notes" binding:"required,min=1,max=200"`
+	Price       helper.Money `json:"price" binding:"required,min=100"`
+	Stock       *int         `json:"stock" binding:"required,min=0,max=100000"`
+	Location    string       `json:"location" binding:"required,min=1,max=200"`
+	IsAvailable *bool        `json:"isAvailable" binding:"required"`
 }
 
 type ProductResponse struct {
-	ID          string    `json:"id"`
-	Name        string    `json:"name"`
-	SKU         string    `json:"sku"`
-	Category    string    `json:"category"`
-	ImageURL    string    `json:"imageUrl"`
-	Notes       string    `json:"notes"`
-	Price       float64   `json:"price"`
-	Stock       int       `json:"stock"`
-	Location    string    `json:"location"`
-	IsAvailable bool      `json:"isAvailable"`
-	CreatedAt   time.Time `json:"createdAt"`
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	SKU         string       `json:"sku"`
+	CategoryID  string       `json:"categoryId"`
+	Category    string       `json:"category"`
+	TaxClassID  string       `json:"taxClassId"`
+	ImageURL    string       `json:"imageUrl"`
+	Notes       string       `json:"notes"`
+	Price       helper.Money `json:"price"`
+	Stock       int          `json:"stock"`
+	Available   int          `json:"available"`
+	Location    string       `json:"location"`
+	IsAvailable bool         `json:"isAvailable"`
+	Type        string       `json:"type"`
+	CreatedAt   time.Time    `json:"createdAt"`
+
+	// Version changes with every write to the product; its ETag is the
+	// version together with the price, stock and available shown
+	Version int `json:"version"`
+
+	// Only set when listing the trash
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
+	// Components of a bundle product
+	BundleItems []BundleItemResponse `json:"bundleItems,omitempty"`
+
+	// Filled when listing with variants=nested
+	Options  []ProductOption   `json:"options,omitempty"`
+	Variants []VariantResponse `json:"variants,omitempty"`
+
+	// Filled when listing with variants=flat
+	VariantID      string            `json:"variantId,omitempty"`
+	VariantOptions map[string]string `json:"variantOptions,omitempty"`
+}
+
+type GetDeletedProductParams struct {
+	Limit  int    `form:"limit,default=5"`
+	Offset int    `form:"offset,default=0"`
+	Name   string `form:"name"`
+	SKU    string `form:"sku"`
 }
 
 type GetProductParams struct {
@@ -35,8 +68,10 @@ type GetProductParams struct {
 	Name        string `form:"name"`
 	IsAvailable string `form:"isAvailable"`
 	Category    string `form:"category"`
+	CategoryID  string `form:"categoryId"`
 	SKU         string `form:"sku"`
 	PriceSort   string `form:"price"`
 	InStock     string `form:"inStock"`
 	CreatedAt   string `form:"createdAt"`
+	Variants    string `form:"variants"`
 }
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+type ProductPriceRequest struct {
+	Price         helper.Money `json:"price" binding:"required,min=100"`
+	EffectiveFrom *time.Time   `json:"effectiveFrom"`
+	EffectiveTo   *time.Time   `json:"effectiveTo"`
+}
+
+type ProductPriceResponse struct {
+	ID            string       `json:"id"`
+	ProductID     string       `json:"productId"`
+	Price         helper.Money `json:"price"`
+	EffectiveFrom time.Time    `json:"effectiveFrom"`
+	EffectiveTo   *time.Time   `json:"effectiveTo"`
+	Status        string       `json:"status"`
+	CreatedAt     time.Time    `json:"createdAt"`
+}
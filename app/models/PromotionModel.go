@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+type PromotionRequest struct {
+	Name            string       `json:"name" binding:"required,min=1,max=50"`
+	Type            string       `json:"type" binding:"required,oneof=percentage fixed buy_x_get_y"`
+	Scope           string       `json:"scope" binding:"required,oneof=product category basket"`
+	ProductID       *string      `json:"productId" binding:"required_if=Scope product,omitempty,numeric"`
+	CategoryID      *string      `json:"categoryId" binding:"required_if=Scope category,omitempty,numeric"`
+	Value           helper.Money `json:"value" binding:"min=0"`
+	BuyQuantity     int          `json:"buyQuantity" binding:"min=0"`
+	GetQuantity     int          `json:"getQuantity" binding:"min=0"`
+	MinBasketAmount helper.Money `json:"minBasketAmount" binding:"min=0"`
+	Code            *string      `json:"code" binding:"omitempty,min=3,max=30,alphanum"`
+	UsageLimit      *int         `json:"usageLimit" binding:"omitempty,min=1"`
+	StartsAt        *time.Time   `json:"startsAt"`
+	EndsAt          *time.Time   `json:"endsAt"`
+	IsActive        *bool        `json:"isActive" binding:"required"`
+}
+
+type PromotionResponse struct {
+	ID              string       `json:"id"`
+	Name            string       `json:"name"`
+	Type            string       `json:"type"`
+	Scope           string       `json:"scope"`
+	ProductID       *string      `json:"productId"`
+	CategoryID      *string      `json:"categoryId"`
+	Value           helper.Money `json:"value"`
+	BuyQuantity     int          `json:"buyQuantity"`
+	GetQuantity     int          `json:"getQuantity"`
+	MinBasketAmount helper.Money `json:"minBasketAmount"`
+	Code            *string      `json:"code"`
+	UsageLimit      *int         `json:"usageLimit"`
+	UsageCount      int          `json:"usageCount"`
+	StartsAt        *time.Time   `json:"startsAt"`
+	EndsAt          *time.Time   `json:"endsAt"`
+	IsActive        bool         `json:"isActive"`
+	CreatedAt       time.Time    `json:"createdAt"`
+}
+
+type GetPromotionParams struct {
+	Limit    int    `form:"limit,default=5"`
+	Offset   int    `form:"offset,default=0"`
+	Scope    string `form:"scope"`
+	Code     string `form:"code"`
+	IsActive string `form:"isActive"`
+}
+
+// AppliedDiscount is one line of the discount breakdown. ProductID is empty
+// for a discount on the whole basket.
+type AppliedDiscount struct {
+	PromotionID string       `json:"promotionId"`
+	Name        string       `json:"name"`
+	ProductID   string       `json:"productId,omitempty"`
+	VariantID   string       `json:"variantId,omitempty"`
+	Amount      helper.Money `json:"amount"`
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+type RefundItemRequest struct {
+	ProductID string `json:"productId" binding:"required,numeric"`
+	VariantID string `json:"variantId" binding:"omitempty,numeric"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// RefundRequest returns the given items, or everything not yet refunded
+// when items is empty. Tender is where the money should go back to; see
+// RefundTenderResponse for where it actually went.
+type RefundRequest struct {
+	Items   []RefundItemRequest `json:"items" binding:"omitempty,dive"`
+	Reason  string              `json:"reason" binding:"required,min=1,max=200"`
+	Restock bool                `json:"restock"`
+	Tender  string              `json:"tender" binding:"required,oneof=cash card ewallet store_credit"`
+}
+
+type RefundItemResponse struct {
+	ProductID string       `json:"productId"`
+	VariantID string       `json:"variantId,omitempty"`
+	Quantity  int          `json:"quantity"`
+	Amount    helper.Money `json:"amount"`
+}
+
+// RefundTenderResponse is the part of a refund paid back through one
+// tender.
+type RefundTenderResponse struct {
+	Method     string       `json:"method"`
+	Amount     helper.Money `json:"amount"`
+	BaseAmount helper.Money `json:"baseAmount"`
+	Reference  string       `json:"reference,omitempty"`
+}
+
+type RefundResponse struct {
+	ID            string                 `json:"id"`
+	TransactionID string                 `json:"transactionId"`
+	Reason        string                 `json:"reason"`
+	Tender        string                 `json:"tender"`
+	Amount        helper.Money           `json:"amount"`
+	BaseAmount    helper.Money           `json:"baseAmount"`
+	Restocked     bool                   `json:"restocked"`
+	Items         []RefundItemResponse   `json:"items"`
+	Tenders       []RefundTenderResponse `json:"tenders"`
+	RefundStatus  string                 `json:"refundStatus"`
+	CreatedAt     time.Time              `json:"createdAt"`
+}
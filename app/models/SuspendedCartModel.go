@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// SuspendCartRequest parks a basket that is not checked out yet.
+type SuspendCartRequest struct {
+	CustomerID     string                  `json:"customerId" binding:"omitempty,numeric"`
+	ProductDetails []CheckoutProductDetail `json:"productDetails" binding:"required,min=1,dive"`
+	PromoCode      string                  `json:"promoCode" binding:"max=30"`
+	Currency       string                  `json:"currency" binding:"omitempty,iso4217"`
+	Note           string                  `json:"note" binding:"max=200"`
+}
+
+type SuspendedCartResponse struct {
+	ID             string                  `json:"id"`
+	StaffID        string                  `json:"staffId"`
+	CustomerID     string                  `json:"customerId,omitempty"`
+	ProductDetails []CheckoutProductDetail `json:"productDetails"`
+	PromoCode      string                  `json:"promoCode,omitempty"`
+	Currency       string                  `json:"currency,omitempty"`
+	Note           string                  `json:"note,omitempty"`
+	ExpiresAt      time.Time               `json:"expiresAt"`
+	CreatedAt      time.Time               `json:"createdAt"`
+}
+
+type GetSuspendedCartParams struct {
+	CustomerID string `form:"customerId"`
+	Limit      int    `form:"limit,default=5"`
+	Offset     int    `form:"offset,default=0"`
+}
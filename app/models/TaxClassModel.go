@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+type TaxClassRequest struct {
+	Name string   `json:"name" binding:"required,min=1,max=50"`
+	Rate *float64 `json:"rate" binding:"required,min=0,max=100"`
+}
+
+type TaxClassResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Rate      float64   `json:"rate"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// TaxClassAssignRequest sets the tax class of a product or category. A
+// null taxClassId removes it, so the product falls back to its category.
+type TaxClassAssignRequest struct {
+	TaxClassID *string `json:"taxClassId" binding:"omitempty,numeric"`
+}
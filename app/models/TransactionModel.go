@@ -0,0 +1,119 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+type CheckoutProductDetail struct {
+	ProductID string `json:"productId" binding:"required,numeric"`
+	VariantID string `json:"variantId" binding:"omitempty,numeric"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+type CheckoutRequest struct {
+	CustomerID     string                  `json:"customerId" binding:"required,numeric"`
+	ProductDetails []CheckoutProductDetail `json:"productDetails" binding:"omitempty,dive"`
+
+	// CartID checks out the lines of a cart instead of ProductDetails
+	CartID string `json:"cartId" binding:"omitempty,numeric"`
+
+	Paid      helper.Money      `json:"paid" binding:"omitempty,min=100"`
+	Change    *helper.Money     `json:"change" binding:"omitempty,min=0"`
+	Payments  []CheckoutPayment `json:"payments" binding:"omitempty,dive"`
+	PromoCode string            `json:"promoCode"`
+	Currency  string            `json:"currency" binding:"omitempty,iso4217"`
+}
+
+// CheckoutPayment is one tender of a sale, in the sale currency. Reference
+// holds e.g. a card approval code or a gift card code.
+type CheckoutPayment struct {
+	Method    string       `json:"method" binding:"required,oneof=cash card ewallet store_credit gift_card points"`
+	Amount    helper.Money `json:"amount" binding:"required,min=1"`
+	Reference string       `json:"reference" binding:"max=100"`
+}
+
+type TransactionPayment struct {
+	Method     string       `json:"method"`
+	Amount     helper.Money `json:"amount"`
+	BaseAmount helper.Money `json:"baseAmount"`
+	Reference  string       `json:"reference,omitempty"`
+}
+
+type CheckoutQuoteRequest struct {
+	ProductDetails []CheckoutProductDetail `json:"productDetails" binding:"required,min=1,dive"`
+	PromoCode      string                  `json:"promoCode"`
+	Currency       string                  `json:"currency" binding:"omitempty,iso4217"`
+}
+
+type CheckoutQuoteResponse struct {
+	ProductDetails []TransactionDetail `json:"productDetails"`
+	Discounts      []AppliedDiscount   `json:"discounts"`
+	Subtotal       helper.Money        `json:"subtotal"`
+	Discount       helper.Money        `json:"discount"`
+	Tax            helper.Money        `json:"tax"`
+	TaxMode        string              `json:"taxMode"`
+	Total          helper.Money        `json:"total"`
+	Currency       string              `json:"currency"`
+	ExchangeRate   json.Number         `json:"exchangeRate"`
+	BaseTotal      helper.Money        `json:"baseTotal"`
+}
+
+type TransactionDetail struct {
+	ProductID string       `json:"productId"`
+	VariantID string       `json:"variantId,omitempty"`
+	Quantity  int          `json:"quantity"`
+	Price     helper.Money `json:"price"`
+	Total     helper.Money `json:"total"`
+	Discount  helper.Money `json:"discount"`
+	TaxRate   float64      `json:"taxRate"`
+	Tax       helper.Money `json:"tax"`
+}
+
+type TransactionResponse struct {
+	TransactionID  string               `json:"transactionId"`
+	CustomerID     string               `json:"customerId"`
+	ProductDetails []TransactionDetail  `json:"productDetails"`
+	Discounts      []AppliedDiscount    `json:"discounts"`
+	Subtotal       helper.Money         `json:"subtotal"`
+	Discount       helper.Money         `json:"discount"`
+	Tax            helper.Money         `json:"tax"`
+	TaxMode        string               `json:"taxMode"`
+	Total          helper.Money         `json:"total"`
+	Payments       []TransactionPayment `json:"payments"`
+	Paid           helper.Money         `json:"paid"`
+	Change         helper.Money         `json:"change"`
+
+	// Amounts above are in Currency, these are their base currency
+	// equivalents at ExchangeRate.
+	Currency     string       `json:"currency"`
+	ExchangeRate json.Number  `json:"exchangeRate"`
+	BaseTotal    helper.Money `json:"baseTotal"`
+	BasePaid     helper.Money `json:"basePaid"`
+	BaseChange   helper.Money `json:"baseChange"`
+
+	// none, partial or refunded
+	RefundStatus string `json:"refundStatus"`
+
+	VoidedAt   *time.Time `json:"voidedAt,omitempty"`
+	VoidReason string     `json:"voidReason,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type VoidTransactionRequest struct {
+	Reason string `json:"reason" binding:"required,min=1,max=200"`
+}
+
+type GetReceiptParams struct {
+	Format string `form:"format" binding:"omitempty,oneof=text html escpos"`
+}
+
+type GetTransactionParams struct {
+	CustomerID string `form:"customerId"`
+	Limit      int    `form:"limit,default=5"`
+	Offset     int    `form:"offset,default=0"`
+	CreatedAt  string `form:"createdAt"`
+}
@@ -1,7 +1,9 @@
 package model
 
+import "time"
+
 type UserRegisterRequest struct {
-	PhoneNumber string `json:"phoneNumber" binding:"required,min=10,max=16"`
+	PhoneNumber string `json:"phoneNumber" binding:"required,min=10,max=20"`
 	Name        string `json:"name" binding:"required,min=5,max=50"`
 	Password    string `json:"password" binding:"required,min=5,max=15"`
 }
@@ -14,7 +16,7 @@ type UserRegisterResponse struct {
 }
 
 type UserLoginRequest struct {
-	PhoneNumber string `json:"phoneNumber" binding:"required,min=10,max=16"`
+	PhoneNumber string `json:"phoneNumber" binding:"required,min=10,max=20"`
 	Password    string `json:"password" binding:"required,min=5,max=15"`
 }
 
@@ -22,3 +24,28 @@ type GetCustomerParams struct {
 	PhoneNumber string `json:"phoneNumber"`
 	Name        string `json:"name"`
 }
+
+// CustomerCreateRequest registers a walk-in customer without a password.
+type CustomerCreateRequest struct {
+	PhoneNumber string `json:"phoneNumber" binding:"required,min=10,max=20"`
+	Name        string `json:"name" binding:"required,min=5,max=50"`
+}
+
+type CustomerCreateResponse struct {
+	UserId      string `json:"userID"`
+	PhoneNumber string `json:"phoneNumber"`
+	Name        string `json:"name"`
+}
+
+type ClaimCodeResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ClaimAccountRequest sets the password of a customer registered by staff,
+// proven by the one-time code they were given.
+type ClaimAccountRequest struct {
+	PhoneNumber string `json:"phoneNumber" binding:"required,min=10,max=20"`
+	Code        string `json:"code" binding:"required,len=6,numeric"`
+	Password    string `json:"password" binding:"required,min=5,max=15"`
+}
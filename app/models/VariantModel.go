@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/Project-Sprint-Golang/EniQilo-Store/helper"
+)
+
+type ProductOption struct {
+	Name   string   `json:"name" binding:"required,min=1,max=30"`
+	Values []string `json:"values" binding:"required,min=1,dive,min=1,max=30"`
+}
+
+type ProductOptionsRequest struct {
+	Options []ProductOption `json:"options" binding:"required,dive"`
+}
+
+type VariantRequest struct {
+	SKU         string            `json:"sku" binding:"required,min=1,max=30"`
+	Options     map[string]string `json:"options" binding:"required"`
+	Price       *helper.Money     `json:"price" binding:"omitempty,min=100"`
+	Stock       *int              `json:"stock" binding:"required,min=0,max=100000"`
+	IsAvailable *bool             `json:"isAvailable" binding:"required"`
+}
+
+type VariantResponse struct {
+	ID            string            `json:"id"`
+	ProductID     string            `json:"productId"`
+	SKU           string            `json:"sku"`
+	Options       map[string]string `json:"options"`
+	PriceOverride *helper.Money     `json:"priceOverride"`
+	Price         helper.Money      `json:"price"`
+	Stock         int               `json:"stock"`
+	Available     int               `json:"available"`
+	IsAvailable   bool              `json:"isAvailable"`
+	CreatedAt     time.Time         `json:"createdAt"`
+}
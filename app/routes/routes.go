@@ -16,14 +16,90 @@ func SetupRouter(r *gin.Engine) {
 		})
 		v1.POST("/staff/register", controller.RegisterStaff)
 		v1.POST("/customer/register", controller.RegisterCustomer)
+		v1.POST("/customer/claim", controller.ClaimCustomerAccount)
 		v1.POST("/staff/login", controller.Login)
 
 		v1.Use(middleware.AuthMiddleware())
-		v1.POST("/product", controller.AddProduct)
+		staff := middleware.RoleMiddleware(middleware.RoleStaff, middleware.RoleSupervisor)
+
+		v1.POST("/product", middleware.IdempotencyMiddleware(), controller.AddProduct)
 		v1.GET("/product", controller.GetAllProduct)
+		v1.GET("/product/:id", controller.GetProduct)
 		v1.PUT("/product/:id", controller.UpdateProduct)
 		v1.DELETE("/product/:id", controller.DeleteProduct)
 		v1.GET("/product/customer", controller.GetSKUProduct)
+		v1.PUT("/product/:id/options", controller.SetProductOptions)
+		v1.GET("/product/:id/variants", controller.GetProductVariants)
+		v1.POST("/product/:id/variants", controller.AddProductVariant)
+		v1.PUT("/product/:id/variants/:variantId", controller.UpdateProductVariant)
+		v1.DELETE("/product/:id/variants/:variantId", controller.DeleteProductVariant)
+		v1.POST("/product/import", controller.ImportProduct)
+		v1.GET("/product/export", controller.ExportProduct)
+		v1.PATCH("/product/bulk", controller.BulkUpdateProduct)
+		v1.DELETE("/product/bulk", controller.BulkDeleteProduct)
+		v1.GET("/product/deleted", controller.GetDeletedProduct)
+		v1.POST("/product/:id/restore", controller.RestoreProduct)
+		v1.GET("/product/:id/history", controller.GetProductHistory)
+		v1.POST("/product/:id/prices", staff, controller.ScheduleProductPrice)
+		v1.GET("/product/:id/prices", controller.GetProductPrices)
+		v1.DELETE("/product/:id/prices/:priceId", staff, controller.CancelProductPrice)
+		v1.PUT("/product/:id/tax-class", staff, controller.SetProductTaxClass)
+		v1.GET("/product/:id/currency-prices", controller.GetProductCurrencyPrices)
+		v1.PUT("/product/:id/currency-prices/:currency", staff, controller.SetProductCurrencyPrice)
+		v1.DELETE("/product/:id/currency-prices/:currency", staff, controller.DeleteProductCurrencyPrice)
+		v1.POST("/product/bundle", controller.AddBundle)
+		v1.PUT("/product/:id/bundle", controller.UpdateBundle)
+
+		v1.POST("/product/checkout", staff, middleware.IdempotencyMiddleware(), controller.Checkout)
+		v1.POST("/product/checkout/quote", staff, controller.CheckoutQuote)
+		v1.GET("/product/checkout/history", controller.GetCheckoutHistory)
+		v1.GET("/transactions/:id/payments", controller.GetTransactionPayments)
+		v1.POST("/transactions/:id/refund", staff, middleware.IdempotencyMiddleware(), controller.RefundTransaction)
+		v1.GET("/transactions/:id/refunds", controller.GetTransactionRefunds)
+		v1.GET("/transactions/:id/receipt", controller.GetTransactionReceipt)
+		v1.POST("/transactions/:id/void", middleware.RoleMiddleware(middleware.RoleSupervisor), controller.VoidTransaction)
+
+		v1.POST("/customer", staff, controller.CreateCustomer)
+		v1.POST("/customer/:id/claim-code", staff, controller.IssueClaimCode)
+		v1.GET("/customer/:id", staff, controller.GetCustomerProfile)
+		v1.PUT("/customer/:id", staff, controller.UpdateCustomerProfile)
+		v1.GET("/customer/:id/transactions", staff, controller.GetCustomerTransactions)
+		v1.GET("/customer/:id/points", staff, controller.GetCustomerPoints)
+		v1.GET("/customer/:id/store-credit", staff, controller.GetCustomerStoreCredit)
+
+		v1.POST("/gift-cards", staff, middleware.IdempotencyMiddleware(), controller.IssueGiftCard)
+		v1.GET("/gift-cards/:code", controller.GetGiftCard)
+
+		v1.POST("/carts", staff, controller.CreateCart)
+		v1.GET("/carts/:id", staff, controller.GetCart)
+		v1.DELETE("/carts/:id", staff, controller.CancelCart)
+		v1.POST("/carts/:id/items", staff, controller.AddCartItem)
+		v1.PUT("/carts/:id/items/:itemId", staff, controller.UpdateCartItem)
+		v1.DELETE("/carts/:id/items/:itemId", staff, controller.DeleteCartItem)
+
+		v1.POST("/cart/suspend", staff, controller.SuspendCart)
+		v1.GET("/cart/suspended", staff, controller.GetSuspendedCarts)
+		v1.POST("/cart/suspended/:id/resume", staff, controller.ResumeSuspendedCart)
+		v1.DELETE("/cart/suspended/:id", staff, controller.DeleteSuspendedCart)
+
+		v1.POST("/category", controller.AddCategory)
+		v1.GET("/category", controller.GetAllCategory)
+		v1.PUT("/category/:id", controller.UpdateCategory)
+		v1.DELETE("/category/:id", controller.DeleteCategory)
+		v1.PUT("/category/:id/tax-class", staff, controller.SetCategoryTaxClass)
+
+		v1.POST("/tax-class", staff, controller.AddTaxClass)
+		v1.GET("/tax-class", controller.GetAllTaxClass)
+		v1.PUT("/tax-class/:id", staff, controller.UpdateTaxClass)
+		v1.DELETE("/tax-class/:id", staff, controller.DeleteTaxClass)
+
+		v1.POST("/exchange-rate", staff, controller.AddExchangeRate)
+		v1.GET("/exchange-rate", controller.GetExchangeRates)
+
+		v1.POST("/promotion", staff, controller.AddPromotion)
+		v1.GET("/promotion", staff, controller.GetAllPromotion)
+		v1.PUT("/promotion/:id", staff, controller.UpdatePromotion)
+		v1.DELETE("/promotion/:id", staff, controller.DeletePromotion)
 
 	}
 
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// GetEnvInt reads an integer setting from the environment, falling back to
+// def when it is unset or not a number.
+func GetEnvInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetEnv reads a string setting from the environment, falling back to def
+// when it is unset.
+func GetEnv(key string, def string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return def
+}
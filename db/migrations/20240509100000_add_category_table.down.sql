@@ -0,0 +1,6 @@
+ALTER TABLE products ADD COLUMN category VARCHAR(255) CHECK (category IN ('Clothing', 'Accessories', 'Footwear', 'Beverages'));
+UPDATE products p SET category = c.name FROM categories c WHERE c.id = p.categoryId AND c.name IN ('Clothing', 'Accessories', 'Footwear', 'Beverages');
+DROP INDEX IF EXISTS idx_products_category_id;
+ALTER TABLE products DROP COLUMN categoryId;
+
+DROP TABLE IF EXISTS categories;
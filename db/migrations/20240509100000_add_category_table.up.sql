@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    parentId INT REFERENCES categories (id),
+    displayOrder INT NOT NULL DEFAULT 0,
+    isActive BOOLEAN NOT NULL DEFAULT true,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP
+);
+
+CREATE INDEX idx_categories_parent_id ON categories (parentId);
+
+-- Seed kategori yang sebelumnya di-hardcode
+INSERT INTO categories (name, displayOrder) VALUES
+    ('Clothing', 1),
+    ('Accessories', 2),
+    ('Footwear', 3),
+    ('Beverages', 4);
+
+ALTER TABLE products ADD COLUMN categoryId INT REFERENCES categories (id);
+UPDATE products p SET categoryId = c.id FROM categories c WHERE c.name = p.category;
+ALTER TABLE products DROP COLUMN category;
+
+CREATE INDEX idx_products_category_id ON products (categoryId);
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS product_options (
+    id SERIAL PRIMARY KEY,
+    productId INT NOT NULL REFERENCES products (id),
+    name VARCHAR(30) NOT NULL,
+    optionValues TEXT[] NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    UNIQUE (productId, name)
+);
+
+CREATE TABLE IF NOT EXISTS product_variants (
+    id SERIAL PRIMARY KEY,
+    productId INT NOT NULL REFERENCES products (id),
+    sku VARCHAR(30) NOT NULL,
+    options JSONB NOT NULL,
+    -- NULL berarti mengikuti harga produk induk
+    price DECIMAL(10, 2),
+    stock INT NOT NULL DEFAULT 0,
+    isAvailable BOOLEAN NOT NULL DEFAULT true,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP
+);
+
+CREATE INDEX idx_product_options_product_id ON product_options (productId);
+CREATE INDEX idx_product_variants_product_id ON product_variants (productId);
+CREATE INDEX idx_product_variants_sku ON product_variants (sku);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS transaction_details;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS bundle_items;
+
+ALTER TABLE products DROP COLUMN bundleDiscount;
+ALTER TABLE products DROP COLUMN bundlePricing;
+ALTER TABLE products DROP COLUMN type;
@@ -0,0 +1,37 @@
+ALTER TABLE products ADD COLUMN type VARCHAR(10) NOT NULL DEFAULT 'single' CHECK (type IN ('single', 'bundle'));
+-- Hanya dipakai untuk bundle: 'fixed' memakai kolom price, 'computed' menjumlahkan harga komponen lalu dipotong bundleDiscount (persen)
+ALTER TABLE products ADD COLUMN bundlePricing VARCHAR(10) CHECK (bundlePricing IN ('fixed', 'computed'));
+ALTER TABLE products ADD COLUMN bundleDiscount DECIMAL(5, 2) NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS bundle_items (
+    id SERIAL PRIMARY KEY,
+    bundleId INT NOT NULL REFERENCES products (id),
+    productId INT NOT NULL REFERENCES products (id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    UNIQUE (bundleId, productId)
+);
+
+CREATE TABLE IF NOT EXISTS transactions (
+    id SERIAL PRIMARY KEY,
+    customerId INT NOT NULL REFERENCES users (id),
+    staffId INT REFERENCES users (id),
+    total DECIMAL(12, 2) NOT NULL,
+    paid DECIMAL(12, 2) NOT NULL,
+    change DECIMAL(12, 2) NOT NULL,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS transaction_details (
+    id SERIAL PRIMARY KEY,
+    transactionId INT NOT NULL REFERENCES transactions (id),
+    productId INT NOT NULL REFERENCES products (id),
+    variantId INT REFERENCES product_variants (id),
+    quantity INT NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    total DECIMAL(12, 2) NOT NULL
+);
+
+CREATE INDEX idx_bundle_items_bundle_id ON bundle_items (bundleId);
+CREATE INDEX idx_transactions_customer_id ON transactions (customerId);
+CREATE INDEX idx_transaction_details_transaction_id ON transaction_details (transactionId);
+CREATE INDEX idx_transaction_details_product_id ON transaction_details (productId);
@@ -0,0 +1,11 @@
+-- Tanpa foreign key ke products agar riwayat tetap ada setelah produk dihapus permanen
+CREATE TABLE IF NOT EXISTS product_audits (
+    id SERIAL PRIMARY KEY,
+    productId INT NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    actorId INT REFERENCES users (id),
+    changes JSONB NOT NULL,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_product_audits_product_id ON product_audits (productId, createdAt);
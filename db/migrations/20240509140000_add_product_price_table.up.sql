@@ -0,0 +1,13 @@
+-- Jadwal harga; bila tidak ada periode yang berlaku, products.price menjadi harga dasar
+CREATE TABLE IF NOT EXISTS product_prices (
+    id SERIAL PRIMARY KEY,
+    productId INT NOT NULL REFERENCES products (id),
+    price DECIMAL(10, 2) NOT NULL,
+    effectiveFrom TIMESTAMP NOT NULL,
+    effectiveTo TIMESTAMP,
+    createdBy INT REFERENCES users (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (effectiveTo IS NULL OR effectiveTo > effectiveFrom)
+);
+
+CREATE INDEX idx_product_prices_product_id ON product_prices (productId, effectiveFrom);
@@ -0,0 +1,5 @@
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS discount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS discount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS subtotal;
+DROP TABLE IF EXISTS transaction_discounts;
+DROP TABLE IF EXISTS promotions;
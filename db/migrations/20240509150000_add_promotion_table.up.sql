@@ -0,0 +1,45 @@
+-- Promosi: diskon persentase/nominal per produk, per kategori, atau per keranjang,
+-- serta beli X gratis Y. Promosi dengan kode hanya berlaku bila kodenya dipakai.
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('percentage', 'fixed', 'buy_x_get_y')),
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('product', 'category', 'basket')),
+    productId INT REFERENCES products (id),
+    categoryId INT REFERENCES categories (id),
+    value DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    buyQuantity INT NOT NULL DEFAULT 0,
+    getQuantity INT NOT NULL DEFAULT 0,
+    minBasketAmount DECIMAL(12, 2) NOT NULL DEFAULT 0,
+    code VARCHAR(30),
+    usageLimit INT,
+    usageCount INT NOT NULL DEFAULT 0,
+    startsAt TIMESTAMP,
+    endsAt TIMESTAMP,
+    isActive BOOLEAN NOT NULL DEFAULT true,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP,
+    CHECK (endsAt IS NULL OR startsAt IS NULL OR endsAt > startsAt)
+);
+
+CREATE UNIQUE INDEX idx_promotions_code ON promotions (lower(code)) WHERE deletedAt IS NULL;
+
+-- Rincian potongan per transaksi; productId kosong berarti potongan keranjang
+CREATE TABLE IF NOT EXISTS transaction_discounts (
+    id SERIAL PRIMARY KEY,
+    transactionId INT NOT NULL REFERENCES transactions (id),
+    promotionId INT NOT NULL REFERENCES promotions (id),
+    productId INT REFERENCES products (id),
+    variantId INT REFERENCES product_variants (id),
+    amount DECIMAL(12, 2) NOT NULL
+);
+
+CREATE INDEX idx_transaction_discounts_transaction_id ON transaction_discounts (transactionId);
+
+ALTER TABLE transactions ADD COLUMN subtotal DECIMAL(12, 2);
+ALTER TABLE transactions ADD COLUMN discount DECIMAL(12, 2) NOT NULL DEFAULT 0;
+UPDATE transactions SET subtotal = total;
+ALTER TABLE transactions ALTER COLUMN subtotal SET NOT NULL;
+
+ALTER TABLE transaction_details ADD COLUMN discount DECIMAL(12, 2) NOT NULL DEFAULT 0;
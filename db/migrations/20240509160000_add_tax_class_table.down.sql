@@ -0,0 +1,8 @@
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS tax;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS taxRate;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS taxClassId;
+ALTER TABLE transactions DROP COLUMN IF EXISTS taxMode;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax;
+ALTER TABLE categories DROP COLUMN IF EXISTS taxClassId;
+ALTER TABLE products DROP COLUMN IF EXISTS taxClassId;
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,20 @@
+-- Kelas pajak (mis. PPN); rate dalam persen
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    rate DECIMAL(5, 2) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deletedAt TIMESTAMP
+);
+
+-- Kelas pajak produk mengalahkan kelas pajak kategori (termasuk kategori induk)
+ALTER TABLE products ADD COLUMN taxClassId INT REFERENCES tax_classes (id);
+ALTER TABLE categories ADD COLUMN taxClassId INT REFERENCES tax_classes (id);
+
+ALTER TABLE transactions ADD COLUMN tax DECIMAL(12, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN taxMode VARCHAR(10) NOT NULL DEFAULT 'exclusive';
+
+ALTER TABLE transaction_details ADD COLUMN taxClassId INT REFERENCES tax_classes (id);
+ALTER TABLE transaction_details ADD COLUMN taxRate DECIMAL(5, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transaction_details ADD COLUMN tax DECIMAL(12, 2) NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS baseChange;
+ALTER TABLE transactions DROP COLUMN IF EXISTS basePaid;
+ALTER TABLE transactions DROP COLUMN IF EXISTS baseTotal;
+ALTER TABLE transactions DROP COLUMN IF EXISTS exchangeRate;
+ALTER TABLE transactions DROP COLUMN IF EXISTS currency;
+DROP TABLE IF EXISTS product_currency_prices;
+DROP TABLE IF EXISTS exchange_rates;
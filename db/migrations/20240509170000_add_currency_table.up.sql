@@ -0,0 +1,33 @@
+-- Kurs terhadap mata uang dasar (BASE_CURRENCY): 1 unit currency = rate unit mata uang dasar
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    currency CHAR(3) NOT NULL,
+    rate DECIMAL(18, 8) NOT NULL CHECK (rate > 0),
+    effectiveFrom TIMESTAMP NOT NULL,
+    createdBy INT REFERENCES users (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_exchange_rates_currency ON exchange_rates (currency, effectiveFrom);
+
+-- Daftar harga per mata uang; bila tidak ada, harga dasar dikonversi dengan kurs
+CREATE TABLE IF NOT EXISTS product_currency_prices (
+    id SERIAL PRIMARY KEY,
+    productId INT NOT NULL REFERENCES products (id),
+    currency CHAR(3) NOT NULL,
+    price DECIMAL(10, 2) NOT NULL,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (productId, currency)
+);
+
+-- Transaksi lama dianggap dalam mata uang dasar; sesuaikan 'IDR' bila BASE_CURRENCY berbeda
+ALTER TABLE transactions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+ALTER TABLE transactions ADD COLUMN exchangeRate DECIMAL(18, 8) NOT NULL DEFAULT 1;
+ALTER TABLE transactions ADD COLUMN baseTotal DECIMAL(12, 2);
+ALTER TABLE transactions ADD COLUMN basePaid DECIMAL(12, 2);
+ALTER TABLE transactions ADD COLUMN baseChange DECIMAL(12, 2);
+UPDATE transactions SET baseTotal = total, basePaid = paid, baseChange = change;
+ALTER TABLE transactions ALTER COLUMN baseTotal SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN basePaid SET NOT NULL;
+ALTER TABLE transactions ALTER COLUMN baseChange SET NOT NULL;
@@ -0,0 +1,16 @@
+-- Pembayaran per transaksi; satu transaksi dapat dibayar dengan beberapa metode
+CREATE TABLE IF NOT EXISTS transaction_payments (
+    id SERIAL PRIMARY KEY,
+    transactionId INT NOT NULL REFERENCES transactions (id),
+    method VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card', 'ewallet', 'store_credit', 'gift_card')),
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    baseAmount DECIMAL(12, 2) NOT NULL,
+    reference VARCHAR(100),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_transaction_payments_transaction_id ON transaction_payments (transactionId);
+
+-- Transaksi lama dibayar tunai sebesar paid
+INSERT INTO transaction_payments (transactionId, method, amount, baseAmount, createdAt)
+SELECT id, 'cash', paid, basePaid, createdAt FROM transactions;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS refund_items;
+DROP TABLE IF EXISTS refunds;
+ALTER TABLE transactions DROP COLUMN IF EXISTS refundStatus;
+ALTER TABLE transaction_details DROP COLUMN IF EXISTS netTotal;
@@ -0,0 +1,45 @@
+-- Nilai bersih per baris (setelah semua diskon, termasuk pajak) sebagai dasar refund
+ALTER TABLE transaction_details ADD COLUMN netTotal DECIMAL(12, 2);
+
+-- Baris lama: diskon keranjang dibagi proporsional terhadap nilai baris
+WITH basket AS (
+    SELECT t.id, t.taxMode,
+        t.subtotal - (SELECT COALESCE(SUM(d.discount), 0) FROM transaction_details d WHERE d.transactionId = t.id) AS lineNet,
+        t.discount - (SELECT COALESCE(SUM(d.discount), 0) FROM transaction_details d WHERE d.transactionId = t.id) AS basketDiscount
+    FROM transactions t
+)
+UPDATE transaction_details td
+SET netTotal = td.total - td.discount
+    - CASE WHEN b.lineNet > 0 THEN ROUND(b.basketDiscount * (td.total - td.discount) / b.lineNet, 2) ELSE 0 END
+    + CASE WHEN b.taxMode = 'exclusive' THEN td.tax ELSE 0 END
+FROM basket b
+WHERE b.id = td.transactionId;
+
+ALTER TABLE transaction_details ALTER COLUMN netTotal SET NOT NULL;
+
+ALTER TABLE transactions ADD COLUMN refundStatus VARCHAR(10) NOT NULL DEFAULT 'none' CHECK (refundStatus IN ('none', 'partial', 'refunded'));
+
+CREATE TABLE IF NOT EXISTS refunds (
+    id SERIAL PRIMARY KEY,
+    transactionId INT NOT NULL REFERENCES transactions (id),
+    staffId INT REFERENCES users (id),
+    reason VARCHAR(200) NOT NULL,
+    tender VARCHAR(20) NOT NULL CHECK (tender IN ('cash', 'card', 'ewallet', 'store_credit')),
+    amount DECIMAL(12, 2) NOT NULL,
+    baseAmount DECIMAL(12, 2) NOT NULL,
+    restocked BOOLEAN NOT NULL DEFAULT false,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_refunds_transaction_id ON refunds (transactionId);
+
+CREATE TABLE IF NOT EXISTS refund_items (
+    id SERIAL PRIMARY KEY,
+    refundId INT NOT NULL REFERENCES refunds (id),
+    transactionDetailId INT NOT NULL REFERENCES transaction_details (id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    amount DECIMAL(12, 2) NOT NULL
+);
+
+CREATE INDEX idx_refund_items_refund_id ON refund_items (refundId);
+CREATE INDEX idx_refund_items_transaction_detail_id ON refund_items (transactionDetailId);
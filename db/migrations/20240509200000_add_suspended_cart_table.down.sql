@@ -0,0 +1,5 @@
+ALTER TABLE transactions DROP COLUMN IF EXISTS voidReason;
+ALTER TABLE transactions DROP COLUMN IF EXISTS voidedBy;
+ALTER TABLE transactions DROP COLUMN IF EXISTS voidedAt;
+
+DROP TABLE IF EXISTS suspended_carts;
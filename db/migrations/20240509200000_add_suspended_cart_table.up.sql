@@ -0,0 +1,24 @@
+-- Role 3 = supervisor (selain 1 = customer, 2 = staff), diberikan langsung di database
+
+-- Keranjang yang diparkir kasir dan bisa dilanjutkan dari terminal mana pun
+CREATE TABLE IF NOT EXISTS suspended_carts (
+    id SERIAL PRIMARY KEY,
+    staffId INT NOT NULL REFERENCES users (id),
+    customerId INT REFERENCES users (id),
+    productDetails JSONB NOT NULL,
+    promoCode VARCHAR(30),
+    currency CHAR(3),
+    note VARCHAR(200),
+    expiresAt TIMESTAMP NOT NULL,
+    resumedAt TIMESTAMP,
+    resumedBy INT REFERENCES users (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Index untuk daftar keranjang yang masih bisa dilanjutkan
+CREATE INDEX idx_suspended_carts_open ON suspended_carts (expiresAt) WHERE resumedAt IS NULL;
+
+-- Transaksi yang dibatalkan (void) oleh supervisor
+ALTER TABLE transactions ADD COLUMN voidedAt TIMESTAMP;
+ALTER TABLE transactions ADD COLUMN voidedBy INT REFERENCES users (id);
+ALTER TABLE transactions ADD COLUMN voidReason VARCHAR(200);
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS carts (
+    id SERIAL PRIMARY KEY,
+    staffId INT NOT NULL REFERENCES users (id),
+    customerId INT REFERENCES users (id),
+    status VARCHAR(15) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'checked_out', 'expired', 'cancelled')),
+    expiresAt TIMESTAMP NOT NULL,
+    transactionId INT REFERENCES transactions (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    id SERIAL PRIMARY KEY,
+    cartId INT NOT NULL REFERENCES carts (id),
+    productId INT NOT NULL REFERENCES products (id),
+    variantId INT REFERENCES product_variants (id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Satu baris per produk/varian dalam satu keranjang
+CREATE UNIQUE INDEX idx_cart_items_line ON cart_items (cartId, productId, COALESCE(variantId, 0));
+
+-- Stok yang ditahan keranjang; bundle menahan stok komponennya
+CREATE TABLE IF NOT EXISTS stock_reservations (
+    id SERIAL PRIMARY KEY,
+    cartId INT NOT NULL REFERENCES carts (id),
+    productId INT REFERENCES products (id),
+    variantId INT REFERENCES product_variants (id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    expiresAt TIMESTAMP NOT NULL,
+    CHECK ((productId IS NULL) <> (variantId IS NULL))
+);
+
+CREATE INDEX idx_stock_reservations_cart_id ON stock_reservations (cartId);
+CREATE INDEX idx_stock_reservations_product_id ON stock_reservations (productId, expiresAt) WHERE productId IS NOT NULL;
+CREATE INDEX idx_stock_reservations_variant_id ON stock_reservations (variantId, expiresAt) WHERE variantId IS NOT NULL;
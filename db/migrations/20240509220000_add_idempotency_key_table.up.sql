@@ -0,0 +1,17 @@
+-- Respons pertama untuk setiap Idempotency-Key, diputar ulang saat request diulang
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    id SERIAL PRIMARY KEY,
+    userId INT NOT NULL REFERENCES users (id),
+    idempotencyKey VARCHAR(255) NOT NULL,
+    requestHash CHAR(64) NOT NULL,
+    status VARCHAR(15) NOT NULL DEFAULT 'processing' CHECK (status IN ('processing', 'completed')),
+    responseStatus INT,
+    responseContentType VARCHAR(100),
+    responseBody BYTEA,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expiresAt TIMESTAMP NOT NULL
+);
+
+-- Key berlaku per pengguna
+CREATE UNIQUE INDEX idx_idempotency_keys_user_key ON idempotency_keys (userId, idempotencyKey);
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expiresAt);
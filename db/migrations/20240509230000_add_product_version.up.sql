@@ -0,0 +1,2 @@
+-- Versi produk untuk ETag / If-Match; naik setiap kali baris produk diubah
+ALTER TABLE products ADD COLUMN version INT NOT NULL DEFAULT 1;
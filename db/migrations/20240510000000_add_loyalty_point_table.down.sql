@@ -0,0 +1,4 @@
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS transaction_payments_method_check;
+ALTER TABLE transaction_payments ADD CONSTRAINT transaction_payments_method_check
+    CHECK (method IN ('cash', 'card', 'ewallet', 'store_credit', 'gift_card'));
+DROP TABLE IF EXISTS points_ledger;
@@ -0,0 +1,22 @@
+-- Mutasi poin loyalitas per pelanggan; saldo adalah jumlah seluruh mutasi
+CREATE TABLE IF NOT EXISTS points_ledger (
+    id SERIAL PRIMARY KEY,
+    customerId INT NOT NULL REFERENCES users (id),
+    transactionId INT REFERENCES transactions (id),
+    refundId INT REFERENCES refunds (id),
+    type VARCHAR(10) NOT NULL CHECK (type IN ('earn', 'redeem', 'expire', 'reverse', 'restore')),
+    points INT NOT NULL CHECK (points <> 0),
+    -- Sisa poin yang belum dipakai dari mutasi masuk, dipakai dari yang paling cepat kedaluwarsa
+    remaining INT NOT NULL DEFAULT 0 CHECK (remaining >= 0),
+    expiresAt TIMESTAMP,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_points_ledger_customer_id ON points_ledger (customerId);
+CREATE INDEX idx_points_ledger_transaction_id ON points_ledger (transactionId);
+CREATE INDEX idx_points_ledger_open ON points_ledger (expiresAt) WHERE remaining > 0;
+
+-- Poin dapat dipakai sebagai metode pembayaran
+ALTER TABLE transaction_payments DROP CONSTRAINT IF EXISTS transaction_payments_method_check;
+ALTER TABLE transaction_payments ADD CONSTRAINT transaction_payments_method_check
+    CHECK (method IN ('cash', 'card', 'ewallet', 'store_credit', 'gift_card', 'points'));
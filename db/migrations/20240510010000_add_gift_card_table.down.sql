@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS gift_card_ledger;
+DROP TABLE IF EXISTS gift_cards;
@@ -0,0 +1,32 @@
+-- Kartu hadiah dan saldo store credit pelanggan; saldo dalam mata uang dasar
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id SERIAL PRIMARY KEY,
+    -- Store credit tidak memiliki kode dan selalu milik satu pelanggan
+    code VARCHAR(32) UNIQUE,
+    kind VARCHAR(15) NOT NULL CHECK (kind IN ('gift_card', 'store_credit')),
+    customerId INT REFERENCES users (id),
+    initialBalance DECIMAL(12, 2) NOT NULL,
+    balance DECIMAL(12, 2) NOT NULL CHECK (balance >= 0),
+    expiresAt TIMESTAMP,
+    issuedBy INT REFERENCES users (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((kind = 'gift_card' AND code IS NOT NULL) OR (kind = 'store_credit' AND customerId IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX idx_gift_cards_store_credit_customer ON gift_cards (customerId) WHERE kind = 'store_credit';
+
+-- Setiap perubahan saldo beserta transaksi atau refund penyebabnya
+CREATE TABLE IF NOT EXISTS gift_card_ledger (
+    id SERIAL PRIMARY KEY,
+    giftCardId INT NOT NULL REFERENCES gift_cards (id),
+    type VARCHAR(10) NOT NULL CHECK (type IN ('issue', 'redeem', 'refund', 'void')),
+    amount DECIMAL(12, 2) NOT NULL,
+    balance DECIMAL(12, 2) NOT NULL,
+    transactionId INT REFERENCES transactions (id),
+    refundId INT REFERENCES refunds (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_gift_card_ledger_gift_card_id ON gift_card_ledger (giftCardId);
+CREATE INDEX idx_gift_card_ledger_transaction_id ON gift_card_ledger (transactionId);
@@ -0,0 +1,15 @@
+-- Data profil opsional pelanggan di luar nomor telepon dan nama
+CREATE TABLE IF NOT EXISTS customer_profiles (
+    customerId INT PRIMARY KEY REFERENCES users (id),
+    email VARCHAR(100),
+    birthday DATE,
+    address VARCHAR(255),
+    marketingConsent BOOLEAN NOT NULL DEFAULT false,
+    -- Waktu persetujuan pemasaran terakhir diberikan atau dicabut
+    marketingConsentAt TIMESTAMP,
+    updatedBy INT REFERENCES users (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customer_profiles_email ON customer_profiles (lower(email));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS customer_claim_codes;
+-- Akun yang belum diklaim tidak akan cocok dengan password apa pun
+UPDATE users SET password = '' WHERE password IS NULL;
+ALTER TABLE users ALTER COLUMN password SET NOT NULL;
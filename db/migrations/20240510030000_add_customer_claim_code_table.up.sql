@@ -0,0 +1,16 @@
+-- Pelanggan yang didaftarkan staf belum memiliki password sampai akunnya diklaim
+ALTER TABLE users ALTER COLUMN password DROP NOT NULL;
+
+-- Kode sekali pakai untuk mengklaim akun pelanggan; hanya hash kode yang disimpan
+CREATE TABLE IF NOT EXISTS customer_claim_codes (
+    id SERIAL PRIMARY KEY,
+    customerId INT NOT NULL REFERENCES users (id),
+    codeHash VARCHAR(100) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expiresAt TIMESTAMP NOT NULL,
+    usedAt TIMESTAMP,
+    createdBy INT REFERENCES users (id),
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customer_claim_codes_customer_id ON customer_claim_codes (customerId);
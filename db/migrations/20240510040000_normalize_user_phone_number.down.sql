@@ -0,0 +1,3 @@
+-- Normalisasi nomor telepon tidak dapat dibatalkan; hanya laporannya yang dihapus
+DROP TABLE IF EXISTS invalid_phone_numbers;
+DROP TABLE IF EXISTS phone_number_collisions;
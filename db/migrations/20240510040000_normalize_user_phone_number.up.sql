@@ -0,0 +1,120 @@
+-- Nomor telepon pengguna yang bentuk E.164-nya sama dengan pengguna lain;
+-- baris ini tidak diubah dan perlu digabung secara manual
+CREATE TABLE IF NOT EXISTS phone_number_collisions (
+    id SERIAL PRIMARY KEY,
+    userId INT NOT NULL REFERENCES users (id),
+    phoneNumber VARCHAR(20) NOT NULL,
+    normalizedPhoneNumber VARCHAR(20) NOT NULL,
+    resolvedAt TIMESTAMP,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Nomor telepon pengguna yang ditolak oleh phone.Normalize; baris ini tidak
+-- diubah dan perlu diperbaiki secara manual
+CREATE TABLE IF NOT EXISTS invalid_phone_numbers (
+    id SERIAL PRIMARY KEY,
+    userId INT NOT NULL REFERENCES users (id),
+    phoneNumber VARCHAR(20) NOT NULL,
+    reason VARCHAR(20) NOT NULL CHECK (reason IN ('missing_plus', 'invalid_character', 'country_code', 'length')),
+    resolvedAt TIMESTAMP,
+    createdAt TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Kode negara dan panjang nomor nasional, sama dengan helper/phone/phone.go
+CREATE TEMPORARY TABLE calling_codes (
+    code VARCHAR(3) PRIMARY KEY,
+    minNational INT NOT NULL DEFAULT 4,
+    maxNational INT NOT NULL DEFAULT 14
+);
+
+INSERT INTO calling_codes (code)
+SELECT unnest(string_to_array(
+    '1 7 ' ||
+    '20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49 51 52 53 54 55 56 57 58 ' ||
+    '60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98 ' ||
+    '211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234 ' ||
+    '235 236 237 238 239 240 241 242 243 244 245 246 247 248 249 250 251 252 253 254 ' ||
+    '255 256 257 258 260 261 262 263 264 265 266 267 268 269 290 291 297 298 299 ' ||
+    '350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 380 ' ||
+    '381 382 383 385 386 387 389 420 421 423 500 501 502 503 504 505 506 507 508 509 ' ||
+    '590 591 592 593 594 595 596 597 598 599 670 672 673 674 675 676 677 678 679 680 ' ||
+    '681 682 683 685 686 687 688 689 690 691 692 850 852 853 855 856 880 886 ' ||
+    '960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 992 993 994 ' ||
+    '995 996 998', ' '));
+
+UPDATE calling_codes cc
+SET minNational = v.minNational, maxNational = v.maxNational
+FROM (VALUES
+    ('1', 10, 10),
+    ('7', 10, 10),
+    ('44', 9, 10),
+    ('60', 8, 10),
+    ('61', 9, 9),
+    ('62', 7, 12),
+    ('65', 8, 8),
+    ('91', 10, 10)
+) AS v (code, minNational, maxNational)
+WHERE cc.code = v.code;
+
+-- Bentuk E.164: '+' diikuti angka saja, tanpa awalan trunk "(0)" pertama;
+-- pemisah yang diizinkan hanya spasi, '-', '.', '(' dan ')'
+CREATE TEMPORARY TABLE normalized_phone_numbers AS
+SELECT s.id, s.phoneNumber, '+' || s.digits AS normalizedPhoneNumber,
+    CASE
+        WHEN NOT s.hasPlus THEN 'missing_plus'
+        WHEN s.rest !~ '^[0-9 .()-]*$' THEN 'invalid_character'
+        WHEN length(s.digits) > 15 THEN 'length'
+        WHEN cc.code IS NULL THEN 'country_code'
+        WHEN length(s.digits) - length(cc.code) NOT BETWEEN cc.minNational AND cc.maxNational THEN 'length'
+    END AS invalidReason
+FROM (
+    SELECT id, phoneNumber, trimmed LIKE '+%' AS hasPlus, rest, regexp_replace(rest, '[^0-9]', '', 'g') AS digits
+    FROM (
+        SELECT id, phoneNumber, btrim(phoneNumber) AS trimmed,
+            regexp_replace(substr(btrim(phoneNumber), 2), '\(0\)', '') AS rest
+        FROM users
+    ) t
+) s
+-- Tidak ada kode negara yang menjadi awalan kode lain, jadi paling banyak satu yang cocok
+LEFT JOIN calling_codes cc ON cc.code IN (left(s.digits, 1), left(s.digits, 2), left(s.digits, 3));
+
+INSERT INTO invalid_phone_numbers (userId, phoneNumber, reason)
+SELECT id, phoneNumber, invalidReason
+FROM normalized_phone_numbers
+WHERE invalidReason IS NOT NULL;
+
+INSERT INTO phone_number_collisions (userId, phoneNumber, normalizedPhoneNumber)
+SELECT n.id, n.phoneNumber, n.normalizedPhoneNumber
+FROM normalized_phone_numbers n
+WHERE n.invalidReason IS NULL
+    AND n.normalizedPhoneNumber IN (
+        SELECT normalizedPhoneNumber FROM normalized_phone_numbers
+        WHERE invalidReason IS NULL
+        GROUP BY normalizedPhoneNumber HAVING COUNT(*) > 1
+    );
+
+UPDATE users u
+SET phoneNumber = n.normalizedPhoneNumber, updatedAt = NOW()
+FROM normalized_phone_numbers n
+WHERE n.id = u.id
+    AND n.invalidReason IS NULL
+    AND u.phoneNumber <> n.normalizedPhoneNumber
+    AND n.id NOT IN (SELECT userId FROM phone_number_collisions);
+
+DO $$
+DECLARE
+    collisions INT;
+    invalid INT;
+BEGIN
+    SELECT COUNT(*) INTO collisions FROM phone_number_collisions;
+    IF collisions > 0 THEN
+        RAISE WARNING '% users share a phone number once normalized to E.164; see phone_number_collisions', collisions;
+    END IF;
+    SELECT COUNT(*) INTO invalid FROM invalid_phone_numbers;
+    IF invalid > 0 THEN
+        RAISE WARNING '% users have a phone number that is not valid E.164; see invalid_phone_numbers', invalid;
+    END IF;
+END $$;
+
+DROP TABLE normalized_phone_numbers;
+DROP TABLE calling_codes;
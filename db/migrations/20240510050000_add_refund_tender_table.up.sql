@@ -0,0 +1,16 @@
+-- Pembagian refund per metode. Refund tunai, kartu dan e-wallet dibatasi sebesar yang
+-- dibayar dengan metode itu; sisanya dikembalikan ke kartu hadiah, poin atau store credit
+CREATE TABLE IF NOT EXISTS refund_tenders (
+    id SERIAL PRIMARY KEY,
+    refundId INT NOT NULL REFERENCES refunds (id),
+    method VARCHAR(20) NOT NULL CHECK (method IN ('cash', 'card', 'ewallet', 'store_credit', 'gift_card', 'points')),
+    amount DECIMAL(12, 2) NOT NULL CHECK (amount > 0),
+    baseAmount DECIMAL(12, 2) NOT NULL,
+    reference VARCHAR(100)
+);
+
+CREATE INDEX idx_refund_tenders_refund_id ON refund_tenders (refundId);
+
+-- Refund lama seluruhnya dibayar dengan metode yang diminta
+INSERT INTO refund_tenders (refundId, method, amount, baseAmount)
+SELECT id, tender, amount, baseAmount FROM refunds WHERE amount > 0;
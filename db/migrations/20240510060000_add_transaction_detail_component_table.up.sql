@@ -0,0 +1,17 @@
+-- Komponen bundle per baris transaksi pada saat penjualan, per satu unit bundle;
+-- refund dan void mengembalikan stok komponen ini walaupun isi bundle sudah diubah
+CREATE TABLE IF NOT EXISTS transaction_detail_components (
+    id SERIAL PRIMARY KEY,
+    transactionDetailId INT NOT NULL REFERENCES transaction_details (id),
+    productId INT NOT NULL REFERENCES products (id),
+    quantity INT NOT NULL CHECK (quantity > 0),
+    UNIQUE (transactionDetailId, productId)
+);
+
+-- Baris lama: isi bundle saat penjualan tidak diketahui, dipakai isi bundle sekarang
+INSERT INTO transaction_detail_components (transactionDetailId, productId, quantity)
+SELECT td.id, bi.productId, bi.quantity
+FROM transaction_details td
+JOIN products p ON p.id = td.productId AND p.type = 'bundle'
+JOIN bundle_items bi ON bi.bundleId = td.productId
+WHERE td.variantId IS NULL;
@@ -0,0 +1,43 @@
+package helper
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var errInvalidRate = errors.New("invalid exchange rate")
+
+// ParseExchangeRate reads a rate such as "15873.01587302": how many units of
+// the base currency one unit of the foreign currency is worth.
+func ParseExchangeRate(s string) (*big.Rat, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || rate.Sign() <= 0 {
+		return nil, errInvalidRate
+	}
+	return rate, nil
+}
+
+// ToBase converts m, in a foreign currency, to the base currency.
+func (m Money) ToBase(rate *big.Rat) Money {
+	return roundRat(new(big.Rat).Mul(big.NewRat(int64(m), 1), rate))
+}
+
+// FromBase converts m, in the base currency, to a foreign currency.
+func (m Money) FromBase(rate *big.Rat) Money {
+	return roundRat(new(big.Rat).Quo(big.NewRat(int64(m), 1), rate))
+}
+
+// roundRat rounds a number of minor units half away from zero.
+func roundRat(r *big.Rat) Money {
+	quotient, remainder := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	remainder.Abs(remainder).Lsh(remainder, 1)
+	if remainder.Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return Money(quotient.Int64())
+}
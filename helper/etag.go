@@ -0,0 +1,36 @@
+package helper
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DerivedETag is the strong ETag of a resource at version whose
+// representation also shows the derived values, which can change without
+// a new version.
+func DerivedETag(version int, derived ...interface{}) string {
+	sum := sha256.Sum256([]byte(fmt.Sprint(derived...)))
+	return `"` + strconv.Itoa(version) + "-" + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ETagMatches reports whether an If-Match or If-None-Match header value
+// lists etag or is "*". If-Match compares strongly, so a weak tag never
+// matches it; If-None-Match compares weakly and ignores the W/ prefix.
+func ETagMatches(header string, etag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package helper
+
+import "testing"
+
+func TestDerivedETag(t *testing.T) {
+	etag := DerivedETag(3, Money(150000), 10, 7)
+	if etag != DerivedETag(3, Money(150000), 10, 7) {
+		t.Error("same version and values give different tags")
+	}
+	for _, other := range []string{
+		DerivedETag(4, Money(150000), 10, 7),
+		DerivedETag(3, Money(140000), 10, 7),
+		DerivedETag(3, Money(150000), 10, 6),
+	} {
+		if other == etag {
+			t.Errorf("%s does not change with the version or a derived value", etag)
+		}
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		etag   string
+		weak   bool
+		want   bool
+	}{
+		{`"3-ab"`, `"3-ab"`, false, true},
+		{`"2-ab", "3-ab"`, `"3-ab"`, false, true},
+		{`*`, `"3-ab"`, false, true},
+		{`W/"3-ab"`, `"3-ab"`, false, false},
+		{`W/"3-ab"`, `"3-ab"`, true, true},
+		{`"3-ac"`, `"3-ab"`, true, false},
+	}
+	for _, tt := range tests {
+		if got := ETagMatches(tt.header, tt.etag, tt.weak); got != tt.want {
+			t.Errorf("ETagMatches(%s, %s, %v) = %v, want %v", tt.header, tt.etag, tt.weak, got, tt.want)
+		}
+	}
+}
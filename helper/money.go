@@ -0,0 +1,186 @@
+package helper
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in minor units (cents) of the store currency. It is
+// written to JSON and to the database as a decimal with two places, so the
+// API and the DECIMAL columns keep their format. Binding tags such as min
+// compare minor units: min=100 means 1.00.
+type Money int64
+
+var errInvalidMoney = errors.New("invalid money amount")
+
+// ParseMoney reads a decimal amount such as "12", "12.5" or "-0.05". More
+// than two decimal places is an error rather than being rounded.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	whole, fraction, _ := strings.Cut(s, ".")
+	// DECIMAL columns may carry trailing zeros beyond two places
+	fraction = strings.TrimRight(fraction, "0")
+	if whole == "" || len(fraction) > 2 {
+		return 0, errInvalidMoney
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || strings.ContainsAny(whole+fraction, "+-") {
+		return 0, errInvalidMoney
+	}
+	if negative {
+		units = -units
+	}
+	return Money(units), nil
+}
+
+// MoneyFromFloat converts a float amount, rounding to the nearest cent.
+func MoneyFromFloat(amount float64) Money {
+	return Money(math.Round(amount * 100))
+}
+
+// Float64 is the amount in major units, for display and percentages only.
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units/100, units%100)
+}
+
+// Mul is the amount for quantity items of price m.
+func (m Money) Mul(quantity int) Money {
+	return m * Money(quantity)
+}
+
+// Percent is rate percent of m, rounded with the given rule. Rates are
+// applied at two decimal places of precision, like the DECIMAL(5,2)
+// columns they come from.
+func (m Money) Percent(rate float64, rounding string) Money {
+	return divRound(int64(m)*basisPoints(rate), 10000, rounding)
+}
+
+// TaxIncluded is the tax contained in a gross amount m taxed at rate
+// percent.
+func (m Money) TaxIncluded(rate float64, rounding string) Money {
+	bp := basisPoints(rate)
+	return divRound(int64(m)*bp, 10000+bp, rounding)
+}
+
+// Share is m split in proportion part/whole, rounded half up.
+func (m Money) Share(part, whole Money) Money {
+	if whole == 0 {
+		return 0
+	}
+	return divRound(int64(m)*int64(part), int64(whole), "half_up")
+}
+
+// MinMoney returns the smaller amount.
+func MinMoney(a, b Money) Money {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a numeric string. The digits are
+// parsed directly, never through a float.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return errInvalidMoney
+	}
+	if strings.ContainsAny(string(number), "eE") {
+		return errInvalidMoney
+	}
+	parsed, err := ParseMoney(string(number))
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Scan reads a DECIMAL column.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case int64:
+		*m = Money(v * 100)
+	case float64:
+		*m = MoneyFromFloat(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	return nil
+}
+
+// Value writes the amount as a decimal string so DECIMAL columns store it
+// exactly.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func basisPoints(rate float64) int64 {
+	return int64(math.Round(rate * 100))
+}
+
+// divRound divides num by a positive den, rounding the quotient with
+// half_up, half_even, up or down. The rule is applied to the magnitude, so
+// up means away from zero and half_up rounds halves away from zero.
+func divRound(num, den int64, rounding string) Money {
+	negative := num < 0
+	if negative {
+		num = -num
+	}
+	quotient, remainder := num/den, num%den
+	switch rounding {
+	case "up":
+		if remainder > 0 {
+			quotient++
+		}
+	case "down":
+	case "half_even":
+		if 2*remainder > den || (2*remainder == den && quotient%2 == 1) {
+			quotient++
+		}
+	default:
+		if 2*remainder >= den {
+			quotient++
+		}
+	}
+	if negative {
+		quotient = -quotient
+	}
+	return Money(quotient)
+}
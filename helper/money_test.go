@@ -0,0 +1,279 @@
+package helper
+
+import (
+	"math/big"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+var roundingModes = []string{"half_up", "half_even", "up", "down"}
+
+// amount is a Money value small enough that products with rates and
+// quantities stay far from overflowing int64.
+type amount Money
+
+func (amount) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(amount(r.Int63n(2_000_000_000_000) - 1_000_000_000_000))
+}
+
+// rate is a percentage with two decimal places between 0 and 100.
+type rate float64
+
+func (rate) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(rate(float64(r.Intn(10001)) / 100))
+}
+
+func check(t *testing.T, f interface{}) {
+	t.Helper()
+	if err := quick.Check(f, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Error(err)
+	}
+}
+
+// roundExact rounds num/den to an integer with the given rule, using exact
+// rational arithmetic; it is the reference the integer code is checked
+// against.
+func roundExact(num, den int64, rounding string) Money {
+	exact := big.NewRat(num, den)
+	floor := new(big.Int).Div(exact.Num(), exact.Denom())
+	fraction := new(big.Rat).Sub(exact, new(big.Rat).SetInt(floor))
+	half := big.NewRat(1, 2)
+
+	// Work on the magnitude so every rule is applied away from zero
+	if exact.Sign() < 0 {
+		return -roundExact(-num, den, rounding)
+	}
+	result := floor.Int64()
+	switch rounding {
+	case "up":
+		if fraction.Sign() > 0 {
+			result++
+		}
+	case "down":
+	case "half_even":
+		if c := fraction.Cmp(half); c > 0 || (c == 0 && result%2 == 1) {
+			result++
+		}
+	default:
+		if fraction.Cmp(half) >= 0 {
+			result++
+		}
+	}
+	return Money(result)
+}
+
+func TestParseMoneyRoundTrip(t *testing.T) {
+	check(t, func(a amount) bool {
+		m := Money(a)
+		parsed, err := ParseMoney(m.String())
+		return err == nil && parsed == m
+	})
+}
+
+func TestParseMoneyRejectsExtraPlaces(t *testing.T) {
+	for _, s := range []string{"1.001", "abc", "", "1.2.3", "--1", "1e3"} {
+		if _, err := ParseMoney(s); err == nil {
+			t.Errorf("ParseMoney(%q) should fail", s)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	check(t, func(a amount) bool {
+		m := Money(a)
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return false
+		}
+		var decoded Money
+		return decoded.UnmarshalJSON(data) == nil && decoded == m
+	})
+}
+
+func TestMul(t *testing.T) {
+	check(t, func(a amount, q1, q2 uint16) bool {
+		m := Money(a)
+		return m.Mul(int(q1)) == m*Money(q1) &&
+			m.Mul(int(q1))+m.Mul(int(q2)) == m.Mul(int(q1)+int(q2))
+	})
+}
+
+func TestPercentRounding(t *testing.T) {
+	for _, mode := range roundingModes {
+		mode := mode
+		t.Run(mode, func(t *testing.T) {
+			check(t, func(a amount, r rate) bool {
+				m := Money(a)
+				return m.Percent(float64(r), mode) == roundExact(int64(m)*basisPoints(float64(r)), 10000, mode)
+			})
+		})
+	}
+}
+
+func TestPercentModesBracketExactValue(t *testing.T) {
+	check(t, func(a amount, r rate) bool {
+		m := Money(a)
+		down, up := m.Percent(float64(r), "down"), m.Percent(float64(r), "up")
+		diff := up - down
+		if diff < 0 {
+			diff = -diff
+		}
+		for _, mode := range []string{"half_up", "half_even"} {
+			p := m.Percent(float64(r), mode)
+			if p != down && p != up {
+				return false
+			}
+		}
+		return diff <= 1
+	})
+}
+
+func TestTaxIncludedRounding(t *testing.T) {
+	for _, mode := range roundingModes {
+		mode := mode
+		t.Run(mode, func(t *testing.T) {
+			check(t, func(a amount, r rate) bool {
+				m := Money(a)
+				bp := basisPoints(float64(r))
+				return m.TaxIncluded(float64(r), mode) == roundExact(int64(m)*bp, 10000+bp, mode)
+			})
+		})
+	}
+}
+
+func TestShareRounding(t *testing.T) {
+	check(t, func(a amount, part, whole uint16) bool {
+		m := Money(a)
+		if whole == 0 {
+			return m.Share(Money(part), 0) == 0
+		}
+		p := Money(part % whole)
+		return m.Share(p, Money(whole)) == roundExact(int64(m)*int64(p), int64(whole), "half_up") &&
+			m.Share(Money(whole), Money(whole)) == m &&
+			m.Share(0, Money(whole)) == 0
+	})
+}
+
+// Shares taken with the last part getting the remainder, the way refunds
+// and basket discounts are split, always add back up to the whole.
+func TestSharePartsSumToWhole(t *testing.T) {
+	check(t, func(a amount, parts []uint16) bool {
+		m := Money(a)
+		var whole Money
+		for _, p := range parts {
+			whole += Money(p)
+		}
+		if whole == 0 {
+			return true
+		}
+		var sum, covered Money
+		for i, p := range parts {
+			share := m.Share(Money(p), whole)
+			if i == len(parts)-1 {
+				share = m - sum
+			} else {
+				// Every share but the last is within half a unit of exact
+				exact := new(big.Rat).SetFrac64(int64(m)*int64(p), int64(whole))
+				diff := new(big.Rat).Sub(exact, big.NewRat(int64(share), 1))
+				if diff.Abs(diff).Cmp(big.NewRat(1, 2)) > 0 {
+					return false
+				}
+			}
+			sum += share
+			covered += Money(p)
+		}
+		return sum == m && covered == whole
+	})
+}
+
+func TestAllocateBasketDiscount(t *testing.T) {
+	check(t, func(values []uint32, fraction uint16) bool {
+		nets := make([]Money, len(values))
+		var basket Money
+		for i, v := range values {
+			nets[i] = Money(v % 10_000_000)
+			basket += nets[i]
+		}
+		discount := basket.Share(Money(fraction), 65535)
+		before := append([]Money(nil), nets...)
+		allocateBasketDiscount(nets, basket, discount)
+
+		var allocated Money
+		for i := range nets {
+			if nets[i] < 0 || nets[i] > before[i] {
+				return false
+			}
+			allocated += before[i] - nets[i]
+		}
+		return allocated == discount
+	})
+}
+
+func TestEvaluatePromotionsLineNetsAddUpToTotal(t *testing.T) {
+	check(t, func(prices []uint32, percent rate, fixed uint32) bool {
+		lines := make([]PromotionLine, len(prices))
+		for i, p := range prices {
+			lines[i] = PromotionLine{ProductID: "1", Quantity: int(p%5) + 1, UnitPrice: Money(p % 1_000_000)}
+		}
+		promotions := []Promotion{
+			{ID: "1", Type: "percentage", Scope: "basket", Value: MoneyFromFloat(float64(percent))},
+			{ID: "2", Type: "fixed", Scope: "basket", Value: Money(fixed % 10_000_000)},
+			{ID: "3", Type: "percentage", Scope: "product", ProductID: "1", Value: 1000},
+		}
+		breakdown := EvaluatePromotions(lines, promotions)
+
+		var nets Money
+		for _, net := range breakdown.LineNets {
+			if net < 0 {
+				return false
+			}
+			nets += net
+		}
+		var discounts Money
+		for _, d := range breakdown.Discounts {
+			discounts += d.Amount
+		}
+		return nets == breakdown.Total &&
+			discounts == breakdown.DiscountTotal &&
+			breakdown.Total == breakdown.Subtotal-breakdown.DiscountTotal
+	})
+}
+
+// exchangeRate is a rate with eight decimal places, the precision exchange
+// rates are stored with, between 0.0001 and 100000. Half of them are below 1
+// so foreign currencies weaker than the base currency are covered too.
+type exchangeRate struct{ *big.Rat }
+
+func (exchangeRate) Generate(r *rand.Rand, size int) reflect.Value {
+	num := r.Int63n(100_000_000-10_000) + 10_000
+	if r.Intn(2) == 0 {
+		num = r.Int63n(10_000_000_000_000-100_000_000) + 100_000_000
+	}
+	return reflect.ValueOf(exchangeRate{big.NewRat(num, 100_000_000)})
+}
+
+// Converting to the base currency is off by at most half a unit. Converting
+// back adds at most half a foreign unit plus the first error divided by the
+// rate, so only rates below 1 can lose a unit on the round trip.
+func TestCurrencyConversionRoundTrip(t *testing.T) {
+	half := big.NewRat(1, 2)
+	check(t, func(a amount, rate exchangeRate) bool {
+		m := Money(a) / 100_000
+		base := m.ToBase(rate.Rat)
+		exact := new(big.Rat).Mul(big.NewRat(int64(m), 1), rate.Rat)
+		diff := new(big.Rat).Sub(exact, big.NewRat(int64(base), 1))
+		if diff.Abs(diff).Cmp(half) > 0 {
+			return false
+		}
+
+		back := base.FromBase(rate.Rat)
+		bound := new(big.Rat).Add(half, new(big.Rat).Quo(half, rate.Rat))
+		diff = big.NewRat(int64(back-m), 1)
+		if diff.Abs(diff).Cmp(bound) > 0 {
+			return false
+		}
+		return rate.Cmp(big.NewRat(1, 1)) < 0 || back == m
+	})
+}
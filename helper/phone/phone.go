@@ -0,0 +1,115 @@
+// Package phone parses phone numbers written in international format and
+// normalizes them to E.164, so the same number is stored and looked up the
+// same way however it was typed.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMissingPlus      = errors.New("phone number must start with '+'")
+	ErrInvalidCharacter = errors.New("phone number contains invalid characters")
+	ErrCountryCode      = errors.New("phone number has an unknown country calling code")
+	ErrLength           = errors.New("phone number has an invalid length")
+)
+
+// maxDigits is the most digits an E.164 number can have, country calling
+// code included.
+const maxDigits = 15
+
+// Numbers of countries without a known length only need a national number
+// of a plausible length.
+const (
+	minNationalDigits = 4
+	maxNationalDigits = 14
+)
+
+// callingCodes are the country calling codes assigned to geographic areas.
+// No code is a prefix of another, so at most one matches a number.
+var callingCodes = map[string]bool{}
+
+func init() {
+	codes := "1 7 " +
+		"20 27 30 31 32 33 34 36 39 40 41 43 44 45 46 47 48 49 51 52 53 54 55 56 57 58 " +
+		"60 61 62 63 64 65 66 81 82 84 86 90 91 92 93 94 95 98 " +
+		"211 212 213 216 218 220 221 222 223 224 225 226 227 228 229 230 231 232 233 234 " +
+		"235 236 237 238 239 240 241 242 243 244 245 246 247 248 249 250 251 252 253 254 " +
+		"255 256 257 258 260 261 262 263 264 265 266 267 268 269 290 291 297 298 299 " +
+		"350 351 352 353 354 355 356 357 358 359 370 371 372 373 374 375 376 377 378 380 " +
+		"381 382 383 385 386 387 389 420 421 423 500 501 502 503 504 505 506 507 508 509 " +
+		"590 591 592 593 594 595 596 597 598 599 670 672 673 674 675 676 677 678 679 680 " +
+		"681 682 683 685 686 687 688 689 690 691 692 850 852 853 855 856 880 886 " +
+		"960 961 962 963 964 965 966 967 968 970 971 972 973 974 975 976 977 992 993 994 " +
+		"995 996 998"
+	for _, code := range strings.Fields(codes) {
+		callingCodes[code] = true
+	}
+}
+
+// nationalLengths are the lengths of the national number in countries
+// whose numbering plan is checked more closely.
+var nationalLengths = map[string][2]int{
+	"1":  {10, 10}, // North American Numbering Plan
+	"7":  {10, 10}, // Russia, Kazakhstan
+	"44": {9, 10},  // United Kingdom
+	"60": {8, 10},  // Malaysia
+	"61": {9, 9},   // Australia
+	"62": {7, 12},  // Indonesia
+	"65": {8, 8},   // Singapore
+	"91": {10, 10}, // India
+}
+
+// Normalize returns raw in E.164 form, such as "+6281234567890". The number
+// must start with '+' and may be grouped with spaces, dashes, dots or
+// parentheses. A national trunk prefix written as "(0)", as in
+// "+44 (0)20 7946 0000", is dropped.
+func Normalize(raw string) (string, error) {
+	s := strings.TrimSpace(raw)
+	if !strings.HasPrefix(s, "+") {
+		return "", ErrMissingPlus
+	}
+	s = strings.Replace(s[1:], "(0)", "", 1)
+
+	digits := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = append(digits, byte(r))
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidCharacter
+		}
+	}
+	if len(digits) > maxDigits {
+		return "", ErrLength
+	}
+
+	number := string(digits)
+	code := callingCode(number)
+	if code == "" {
+		return "", ErrCountryCode
+	}
+
+	national := len(number) - len(code)
+	min, max := minNationalDigits, maxNationalDigits
+	if lengths, ok := nationalLengths[code]; ok {
+		min, max = lengths[0], lengths[1]
+	}
+	if national < min || national > max {
+		return "", ErrLength
+	}
+	return "+" + number, nil
+}
+
+// callingCode finds the country calling code that number, written without
+// its '+', starts with.
+func callingCode(number string) string {
+	for n := 1; n <= 3 && n <= len(number); n++ {
+		if callingCodes[number[:n]] {
+			return number[:n]
+		}
+	}
+	return ""
+}
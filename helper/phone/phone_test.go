@@ -0,0 +1,125 @@
+package phone
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+		err  error
+	}{
+		{"+6281234567890", "+6281234567890", nil},
+		{"  +62-812-3456-7890 ", "+6281234567890", nil},
+		{"+62 (812) 3456.7890", "+6281234567890", nil},
+		{"+1 (555) 123-4567", "+15551234567", nil},
+
+		// Trunk prefix; only the first "(0)" is dropped
+		{"+62 (0)812 3456 789", "+628123456789", nil},
+		{"+44 (0)20 7946 0000", "+442079460000", nil},
+		{"+44 (0)20 (0)794 6000", "+442007946000", nil},
+		{"+44 (0)(0)20 7946 000", "+440207946000", nil},
+
+		// Format
+		{"6281234567890", "", ErrMissingPlus},
+		{"", "", ErrMissingPlus},
+		{"+62 812 3456 7890 ext 1", "", ErrInvalidCharacter},
+		{"+62/812/3456/7890", "", ErrInvalidCharacter},
+		{"++6281234567890", "", ErrInvalidCharacter},
+
+		// Calling codes
+		{"+999123456", "", ErrCountryCode},
+		{"+0812345678", "", ErrCountryCode},
+		{"+", "", ErrCountryCode},
+		{"+2421234567", "+2421234567", nil},
+
+		// Per-country lengths
+		{"+1555123456", "", ErrLength},
+		{"+155512345678", "", ErrLength},
+		{"+79161234567", "+79161234567", nil},
+		{"+442079460000", "+442079460000", nil},
+		{"+44207946000", "+44207946000", nil},
+		{"+4420794600000", "", ErrLength},
+		{"+6012345678", "+6012345678", nil},
+		{"+601234567", "", ErrLength},
+		{"+61412345678", "+61412345678", nil},
+		{"+614123456789", "", ErrLength},
+		{"+621234567", "+621234567", nil},
+		{"+62123456", "", ErrLength},
+		{"+62812345678901", "+62812345678901", nil},
+		{"+628123456789012", "", ErrLength},
+		{"+6512345678", "+6512345678", nil},
+		{"+6512345", "", ErrLength},
+		{"+919876543210", "+919876543210", nil},
+		{"+91987654321", "", ErrLength},
+
+		// Countries without a known length
+		{"+49301", "", ErrLength},
+		{"+491234", "+491234", nil},
+		{"+4912345678901234", "", ErrLength},
+		{"+2421234567890123", "", ErrLength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := Normalize(tt.raw)
+			if err != tt.err || got != tt.want {
+				t.Errorf("Normalize(%q) = %q, %v; want %q, %v", tt.raw, got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestCallingCodesArePrefixFree(t *testing.T) {
+	for code := range callingCodes {
+		for n := 1; n < len(code); n++ {
+			if callingCodes[code[:n]] {
+				t.Errorf("calling code %s starts with calling code %s", code, code[:n])
+			}
+		}
+	}
+}
+
+// The phone number migration normalizes existing users in SQL and has to
+// accept exactly what Normalize accepts.
+func TestMigrationMatchesNumberingPlan(t *testing.T) {
+	data, err := os.ReadFile("../../db/migrations/20240510040000_normalize_user_phone_number.up.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := string(data)
+
+	start := strings.Index(sql, "INSERT INTO calling_codes")
+	end := strings.Index(sql, "UPDATE calling_codes")
+	if start < 0 || end < start {
+		t.Fatal("calling codes not found in migration")
+	}
+	codes := map[string]bool{}
+	for _, m := range regexp.MustCompile(`'([0-9 ]+)'`).FindAllStringSubmatch(sql[start:end], -1) {
+		for _, code := range strings.Fields(m[1]) {
+			codes[code] = true
+		}
+	}
+	if !reflect.DeepEqual(codes, callingCodes) {
+		t.Errorf("migration calling codes differ from callingCodes")
+	}
+
+	lengths := map[string][2]int{}
+	for _, m := range regexp.MustCompile(`\('([0-9]+)', ([0-9]+), ([0-9]+)\)`).FindAllStringSubmatch(sql[end:], -1) {
+		min, _ := strconv.Atoi(m[2])
+		max, _ := strconv.Atoi(m[3])
+		lengths[m[1]] = [2]int{min, max}
+	}
+	if !reflect.DeepEqual(lengths, nationalLengths) {
+		t.Errorf("migration national lengths %v differ from %v", lengths, nationalLengths)
+	}
+	if !strings.Contains(sql, "DEFAULT "+strconv.Itoa(minNationalDigits)) || !strings.Contains(sql, "DEFAULT "+strconv.Itoa(maxNationalDigits)) ||
+		!strings.Contains(sql, "> "+strconv.Itoa(maxDigits)) {
+		t.Errorf("migration default lengths differ from the package constants")
+	}
+}
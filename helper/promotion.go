@@ -0,0 +1,181 @@
+package helper
+
+// PromotionLine is one basket line as seen by the promotion engine.
+// CategoryIDs holds the product's category and all of its ancestors, so a
+// category promotion also covers subcategories.
+type PromotionLine struct {
+	ProductID   string
+	CategoryIDs []string
+	Quantity    int
+	UnitPrice   Money
+}
+
+// Promotion is an active promotion the basket may qualify for. Value is a
+// percentage for percentage promotions and an amount for fixed ones.
+type Promotion struct {
+	ID              string
+	Name            string
+	Type            string // percentage, fixed or buy_x_get_y
+	Scope           string // product, category or basket
+	ProductID       string
+	CategoryID      string
+	Value           Money
+	BuyQuantity     int
+	GetQuantity     int
+	MinBasketAmount Money
+}
+
+// Discount is a promotion applied to a line (LineIndex >= 0) or to the
+// whole basket (LineIndex == -1).
+type Discount struct {
+	PromotionID string
+	Name        string
+	LineIndex   int
+	Amount      Money
+}
+
+// PriceBreakdown is the itemized result of evaluating promotions. LineNets
+// is what every line costs after its own discount and its share of the
+// basket discount.
+type PriceBreakdown struct {
+	LineTotals    []Money
+	LineDiscounts []Money
+	LineNets      []Money
+	Discounts     []Discount
+	Subtotal      Money
+	DiscountTotal Money
+	Total         Money
+}
+
+func (p Promotion) appliesTo(line PromotionLine) bool {
+	switch p.Scope {
+	case "product":
+		return p.ProductID == line.ProductID
+	case "category":
+		for _, id := range line.CategoryIDs {
+			if id == p.CategoryID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lineDiscount is the amount p takes off line, never more than the line is
+// worth.
+func (p Promotion) lineDiscount(line PromotionLine, lineTotal Money) Money {
+	var amount Money
+	switch p.Type {
+	case "percentage":
+		amount = lineTotal.Percent(p.Value.Float64(), "half_up")
+	case "fixed":
+		amount = p.Value.Mul(line.Quantity)
+	case "buy_x_get_y":
+		if group := p.BuyQuantity + p.GetQuantity; group > 0 && p.GetQuantity > 0 {
+			free := line.Quantity / group * p.GetQuantity
+			amount = line.UnitPrice.Mul(free)
+		}
+	}
+	return MinMoney(amount, lineTotal)
+}
+
+func (p Promotion) basketDiscount(amount Money) Money {
+	var discount Money
+	switch p.Type {
+	case "percentage":
+		discount = amount.Percent(p.Value.Float64(), "half_up")
+	case "fixed":
+		discount = p.Value
+	}
+	return MinMoney(discount, amount)
+}
+
+// EvaluatePromotions prices the basket. Promotions do not stack: every line
+// gets the single best product or category promotion it qualifies for, then
+// the single best basket promotion is applied to what is left. A
+// promotion's minimum basket amount is checked against the undiscounted
+// subtotal.
+func EvaluatePromotions(lines []PromotionLine, promotions []Promotion) PriceBreakdown {
+	breakdown := PriceBreakdown{
+		LineTotals:    make([]Money, len(lines)),
+		LineDiscounts: make([]Money, len(lines)),
+		LineNets:      make([]Money, len(lines)),
+		Discounts:     []Discount{},
+	}
+	for i, line := range lines {
+		breakdown.LineTotals[i] = line.UnitPrice.Mul(line.Quantity)
+		breakdown.Subtotal += breakdown.LineTotals[i]
+	}
+
+	var eligible []Promotion
+	for _, p := range promotions {
+		if breakdown.Subtotal >= p.MinBasketAmount {
+			eligible = append(eligible, p)
+		}
+	}
+
+	for i, line := range lines {
+		var best *Discount
+		for _, p := range eligible {
+			if p.Scope == "basket" || !p.appliesTo(line) {
+				continue
+			}
+			amount := p.lineDiscount(line, breakdown.LineTotals[i])
+			if amount > 0 && (best == nil || amount > best.Amount) {
+				best = &Discount{PromotionID: p.ID, Name: p.Name, LineIndex: i, Amount: amount}
+			}
+		}
+		if best != nil {
+			breakdown.LineDiscounts[i] = best.Amount
+			breakdown.DiscountTotal += best.Amount
+			breakdown.Discounts = append(breakdown.Discounts, *best)
+		}
+	}
+
+	remaining := breakdown.Subtotal - breakdown.DiscountTotal
+	var bestBasket *Discount
+	for _, p := range eligible {
+		if p.Scope != "basket" {
+			continue
+		}
+		amount := p.basketDiscount(remaining)
+		if amount > 0 && (bestBasket == nil || amount > bestBasket.Amount) {
+			bestBasket = &Discount{PromotionID: p.ID, Name: p.Name, LineIndex: -1, Amount: amount}
+		}
+	}
+	for i := range lines {
+		breakdown.LineNets[i] = breakdown.LineTotals[i] - breakdown.LineDiscounts[i]
+	}
+	if bestBasket != nil {
+		breakdown.DiscountTotal += bestBasket.Amount
+		breakdown.Discounts = append(breakdown.Discounts, *bestBasket)
+		allocateBasketDiscount(breakdown.LineNets, remaining, bestBasket.Amount)
+	}
+
+	breakdown.Total = breakdown.Subtotal - breakdown.DiscountTotal
+	return breakdown
+}
+
+// allocateBasketDiscount spreads a basket discount over the lines in
+// proportion to their value. The last line with a value takes the rounding
+// remainder so the shares add up to the discount exactly.
+func allocateBasketDiscount(nets []Money, basket, discount Money) {
+	last := -1
+	for i, net := range nets {
+		if net > 0 {
+			last = i
+		}
+	}
+	var allocated Money
+	for i, net := range nets {
+		if net <= 0 {
+			continue
+		}
+		share := discount.Share(net, basket)
+		if i == last {
+			share = discount - allocated
+		}
+		allocated += share
+		nets[i] = net - share
+	}
+}
@@ -0,0 +1,175 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEvaluatePromotions(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []PromotionLine
+		promotions []Promotion
+		discounts  []Discount
+		nets       []Money
+		total      Money
+	}{
+		{
+			"best promotion per line",
+			[]PromotionLine{{ProductID: "1", CategoryIDs: []string{"10", "1"}, Quantity: 2, UnitPrice: 1000}},
+			[]Promotion{
+				{ID: "percentage", Type: "percentage", Scope: "product", ProductID: "1", Value: 1000},
+				{ID: "fixed", Type: "fixed", Scope: "category", CategoryID: "1", Value: 150},
+				{ID: "other", Type: "percentage", Scope: "product", ProductID: "2", Value: 5000},
+			},
+			[]Discount{{PromotionID: "fixed", LineIndex: 0, Amount: 300}},
+			[]Money{1700},
+			1700,
+		},
+		{
+			"promotions do not stack on a line",
+			[]PromotionLine{
+				{ProductID: "1", Quantity: 1, UnitPrice: 1000},
+				{ProductID: "2", Quantity: 1, UnitPrice: 500},
+			},
+			[]Promotion{
+				{ID: "a", Type: "percentage", Scope: "product", ProductID: "1", Value: 2000},
+				{ID: "b", Type: "percentage", Scope: "product", ProductID: "1", Value: 1000},
+			},
+			[]Discount{{PromotionID: "a", LineIndex: 0, Amount: 200}},
+			[]Money{800, 500},
+			1300,
+		},
+		{
+			"fixed never exceeds the line",
+			[]PromotionLine{{ProductID: "1", Quantity: 2, UnitPrice: 300}},
+			[]Promotion{{ID: "fixed", Type: "fixed", Scope: "product", ProductID: "1", Value: 500}},
+			[]Discount{{PromotionID: "fixed", LineIndex: 0, Amount: 600}},
+			[]Money{0},
+			0,
+		},
+		{
+			"buy 2 get 1 counts whole groups",
+			[]PromotionLine{
+				{ProductID: "1", Quantity: 7, UnitPrice: 1000},
+				{ProductID: "1", Quantity: 2, UnitPrice: 1000},
+				{ProductID: "1", Quantity: 3, UnitPrice: 400},
+			},
+			[]Promotion{{ID: "b2g1", Type: "buy_x_get_y", Scope: "product", ProductID: "1", BuyQuantity: 2, GetQuantity: 1}},
+			[]Discount{
+				{PromotionID: "b2g1", LineIndex: 0, Amount: 2000},
+				{PromotionID: "b2g1", LineIndex: 2, Amount: 400},
+			},
+			[]Money{5000, 2000, 800},
+			7800,
+		},
+		{
+			"buy 3 get 2",
+			[]PromotionLine{{ProductID: "1", Quantity: 11, UnitPrice: 100}},
+			[]Promotion{{ID: "b3g2", Type: "buy_x_get_y", Scope: "product", ProductID: "1", BuyQuantity: 3, GetQuantity: 2}},
+			[]Discount{{PromotionID: "b3g2", LineIndex: 0, Amount: 400}},
+			[]Money{700},
+			700,
+		},
+		{
+			"minimum basket amount is checked against the subtotal",
+			[]PromotionLine{{ProductID: "1", Quantity: 1, UnitPrice: 1000}},
+			[]Promotion{
+				{ID: "big", Type: "percentage", Scope: "product", ProductID: "1", Value: 5000, MinBasketAmount: 1001},
+				{ID: "small", Type: "percentage", Scope: "product", ProductID: "1", Value: 1000, MinBasketAmount: 1000},
+			},
+			[]Discount{{PromotionID: "small", LineIndex: 0, Amount: 100}},
+			[]Money{900},
+			900,
+		},
+		{
+			"best basket promotion applies after line discounts",
+			[]PromotionLine{
+				{ProductID: "1", Quantity: 1, UnitPrice: 1000},
+				{ProductID: "2", Quantity: 1, UnitPrice: 3000},
+			},
+			[]Promotion{
+				{ID: "line", Type: "percentage", Scope: "product", ProductID: "1", Value: 1000},
+				{ID: "percentage", Type: "percentage", Scope: "basket", Value: 1000},
+				{ID: "fixed", Type: "fixed", Scope: "basket", Value: 300},
+			},
+			[]Discount{
+				{PromotionID: "line", LineIndex: 0, Amount: 100},
+				{PromotionID: "percentage", LineIndex: -1, Amount: 390},
+			},
+			[]Money{810, 2700},
+			3510,
+		},
+		{
+			"basket remainder goes to the last line",
+			[]PromotionLine{
+				{ProductID: "1", Quantity: 1, UnitPrice: 333},
+				{ProductID: "2", Quantity: 1, UnitPrice: 333},
+				{ProductID: "3", Quantity: 1, UnitPrice: 334},
+			},
+			[]Promotion{{ID: "fixed", Type: "fixed", Scope: "basket", Value: 100}},
+			[]Discount{{PromotionID: "fixed", LineIndex: -1, Amount: 100}},
+			[]Money{300, 300, 300},
+			900,
+		},
+		{
+			"fixed basket never exceeds the basket",
+			[]PromotionLine{{ProductID: "1", Quantity: 1, UnitPrice: 250}},
+			[]Promotion{{ID: "fixed", Type: "fixed", Scope: "basket", Value: 1000}},
+			[]Discount{{PromotionID: "fixed", LineIndex: -1, Amount: 250}},
+			[]Money{0},
+			0,
+		},
+		{
+			"no promotions",
+			[]PromotionLine{{ProductID: "1", Quantity: 3, UnitPrice: 250}},
+			nil,
+			[]Discount{},
+			[]Money{750},
+			750,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluatePromotions(tt.lines, tt.promotions)
+			if !reflect.DeepEqual(got.Discounts, tt.discounts) {
+				t.Errorf("discounts = %+v, want %+v", got.Discounts, tt.discounts)
+			}
+			if !reflect.DeepEqual(got.LineNets, tt.nets) {
+				t.Errorf("line nets = %v, want %v", got.LineNets, tt.nets)
+			}
+			if got.Total != tt.total || got.Subtotal-got.DiscountTotal != tt.total {
+				t.Errorf("subtotal, discount, total = %v, %v, %v; want total %v", got.Subtotal, got.DiscountTotal, got.Total, tt.total)
+			}
+		})
+	}
+}
+
+func TestAllocateBasketDiscountShares(t *testing.T) {
+	tests := []struct {
+		name     string
+		nets     []Money
+		discount Money
+		want     []Money
+	}{
+		{"proportional", []Money{1000, 3000}, 400, []Money{900, 2700}},
+		{"remainder on the last line", []Money{333, 333, 334}, 100, []Money{300, 300, 300}},
+		{"rounded share then remainder", []Money{500, 500}, 3, []Money{498, 499}},
+		{"last line without value is skipped", []Money{500, 500, 0}, 3, []Money{498, 499, 0}},
+		{"free lines keep nothing", []Money{0, 700, 0}, 700, []Money{0, 0, 0}},
+		{"no discount", []Money{100, 200}, 0, []Money{100, 200}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nets := append([]Money(nil), tt.nets...)
+			var basket Money
+			for _, net := range nets {
+				basket += net
+			}
+			allocateBasketDiscount(nets, basket, tt.discount)
+			if !reflect.DeepEqual(nets, tt.want) {
+				t.Errorf("got %v, want %v", nets, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package helper
+
+import (
+	"bytes"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ReceiptLine is a sold item as printed on a receipt. Discount is the
+// discount taken off the line, labelled with the promotions that gave it.
+type ReceiptLine struct {
+	Name          string
+	Quantity      int
+	Price         Money
+	Total         Money
+	Discount      Money
+	DiscountLabel string
+}
+
+// ReceiptAmount is a labelled amount, such as a total or a tender.
+type ReceiptAmount struct {
+	Label  string
+	Amount Money
+}
+
+// Receipt is everything printed on a receipt, already resolved to text.
+// Header and Footer are the rendered store templates, one entry per line.
+type Receipt struct {
+	Header   []string
+	Info     []string
+	Lines    []ReceiptLine
+	Totals   []ReceiptAmount
+	Total    ReceiptAmount
+	Payments []ReceiptAmount
+	Change   Money
+	Notices  []string
+	Footer   []string
+}
+
+// receiptRow lays out a left and a right column in width characters. A
+// left column too long for the row is cut short.
+func receiptRow(left, right string, width int) string {
+	space := width - utf8.RuneCountInString(right) - 1
+	if space < 0 {
+		space = 0
+	}
+	if utf8.RuneCountInString(left) > space {
+		left = string([]rune(left)[:space])
+	}
+	pad := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if pad < 1 {
+		pad = 1
+	}
+	return left + strings.Repeat(" ", pad) + right
+}
+
+func receiptCenter(text string, width int) string {
+	n := utf8.RuneCountInString(text)
+	if n >= width {
+		return string([]rune(text)[:width])
+	}
+	return strings.Repeat(" ", (width-n)/2) + text
+}
+
+// textSections renders the receipt as fixed-width sections: the header,
+// the body and the total line, and the rest. ESC/POS output prints the
+// same sections with its own alignment and emphasis.
+func (r Receipt) textSections(width int) (header []string, body []string, total string, rest []string) {
+	rule := strings.Repeat("-", width)
+	header = r.Header
+
+	body = append(body, rule)
+	body = append(body, r.Info...)
+	body = append(body, rule)
+	for _, line := range r.Lines {
+		body = append(body, line.Name)
+		body = append(body, receiptRow("  "+strconv.Itoa(line.Quantity)+" x "+line.Price.String(), line.Total.String(), width))
+		if line.Discount != 0 {
+			body = append(body, receiptRow("  "+line.DiscountLabel, (-line.Discount).String(), width))
+		}
+	}
+	body = append(body, rule)
+	for _, amount := range r.Totals {
+		body = append(body, receiptRow(amount.Label, amount.Amount.String(), width))
+	}
+	total = receiptRow(r.Total.Label, r.Total.Amount.String(), width)
+
+	rest = append(rest, rule)
+	for _, payment := range r.Payments {
+		rest = append(rest, receiptRow(payment.Label, payment.Amount.String(), width))
+	}
+	rest = append(rest, receiptRow("Change", r.Change.String(), width))
+	if len(r.Notices) > 0 {
+		rest = append(rest, rule)
+		rest = append(rest, r.Notices...)
+	}
+	rest = append(rest, rule)
+	return header, body, total, rest
+}
+
+// Text renders the receipt as plain text for a printer with width
+// characters per line.
+func (r Receipt) Text(width int) string {
+	header, body, total, rest := r.textSections(width)
+	var b strings.Builder
+	for _, line := range header {
+		b.WriteString(receiptCenter(line, width) + "\n")
+	}
+	for _, line := range append(append(body, total), rest...) {
+		b.WriteString(line + "\n")
+	}
+	for _, line := range r.Footer {
+		b.WriteString(receiptCenter(line, width) + "\n")
+	}
+	return b.String()
+}
+
+// ESC/POS commands used for receipts
+var (
+	escposInit        = []byte{0x1b, 0x40}
+	escposAlignLeft   = []byte{0x1b, 0x61, 0x00}
+	escposAlignCenter = []byte{0x1b, 0x61, 0x01}
+	escposBoldOn      = []byte{0x1b, 0x45, 0x01}
+	escposBoldOff     = []byte{0x1b, 0x45, 0x00}
+	escposDoubleOn    = []byte{0x1d, 0x21, 0x11}
+	escposDoubleOff   = []byte{0x1d, 0x21, 0x00}
+	escposFeedAndCut  = []byte{0x1b, 0x64, 0x04, 0x1d, 0x56, 0x42, 0x00}
+)
+
+// escposText keeps printable ASCII only; printers differ in the code pages
+// they support, so anything else is printed as "?".
+func escposText(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e {
+			r = '?'
+		}
+		out = append(out, byte(r))
+	}
+	return append(out, '\n')
+}
+
+// ESCPOS renders the receipt as an ESC/POS byte stream for a thermal
+// printer with width characters per line: a centered, double size header,
+// a bold total and a paper cut at the end.
+func (r Receipt) ESCPOS(width int) []byte {
+	header, body, total, rest := r.textSections(width)
+	var b bytes.Buffer
+	b.Write(escposInit)
+
+	b.Write(escposAlignCenter)
+	for i, line := range header {
+		if i == 0 {
+			b.Write(escposDoubleOn)
+			b.Write(escposText(line))
+			b.Write(escposDoubleOff)
+			continue
+		}
+		b.Write(escposText(line))
+	}
+
+	b.Write(escposAlignLeft)
+	for _, line := range body {
+		b.Write(escposText(line))
+	}
+	b.Write(escposBoldOn)
+	b.Write(escposText(total))
+	b.Write(escposBoldOff)
+	for _, line := range rest {
+		b.Write(escposText(line))
+	}
+
+	b.Write(escposAlignCenter)
+	for _, line := range r.Footer {
+		b.Write(escposText(line))
+	}
+	b.Write(escposFeedAndCut)
+	return b.Bytes()
+}
+
+var receiptHTML = template.Must(template.New("receipt").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Receipt</title>
+<style>
+body { font-family: monospace; max-width: 24em; margin: 1em auto; }
+.center { text-align: center; }
+table { width: 100%; border-collapse: collapse; }
+td.amount { text-align: right; white-space: nowrap; }
+td.detail { padding-left: 1em; }
+tr.total td { font-weight: bold; border-top: 1px solid; }
+hr { border: 0; border-top: 1px dashed; }
+</style>
+</head>
+<body>
+<div class="center">{{range .Header}}<div>{{.}}</div>{{end}}</div>
+<hr>
+{{range .Info}}<div>{{.}}</div>{{end}}
+<hr>
+<table>
+{{range .Lines}}<tr><td colspan="2">{{.Name}}</td></tr>
+<tr><td class="detail">{{.Quantity}} x {{.Price}}</td><td class="amount">{{.Total}}</td></tr>
+{{if .Discount}}<tr><td class="detail">{{.DiscountLabel}}</td><td class="amount">-{{.Discount}}</td></tr>
+{{end}}{{end}}</table>
+<hr>
+<table>
+{{range .Totals}}<tr><td>{{.Label}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}<tr class="total"><td>{{.Total.Label}}</td><td class="amount">{{.Total.Amount}}</td></tr>
+</table>
+<hr>
+<table>
+{{range .Payments}}<tr><td>{{.Label}}</td><td class="amount">{{.Amount}}</td></tr>
+{{end}}<tr><td>Change</td><td class="amount">{{.Change}}</td></tr>
+</table>
+{{if .Notices}}<hr>
+{{range .Notices}}<div><strong>{{.}}</strong></div>{{end}}{{end}}
+<hr>
+<div class="center">{{range .Footer}}<div>{{.}}</div>{{end}}</div>
+</body>
+</html>
+`))
+
+// HTML renders the receipt as an HTML page; all text is escaped.
+func (r Receipt) HTML() (string, error) {
+	var b strings.Builder
+	err := receiptHTML.Execute(&b, r)
+	return b.String(), err
+}
@@ -0,0 +1,61 @@
+package helper
+
+// TaxRules describes how a store charges tax.
+type TaxRules struct {
+	// Inclusive means prices already contain tax, otherwise tax is added on
+	// top of them.
+	Inclusive bool
+	// Rounding is half_up, half_even, up or down.
+	Rounding string
+	// PerLine rounds the tax of every line and sums them. Otherwise the
+	// transaction tax is rounded once from the unrounded line taxes.
+	PerLine bool
+}
+
+// TaxBreakdown is the tax of every line and of the whole transaction. Total
+// is the amount payable including tax.
+type TaxBreakdown struct {
+	LineTaxes []Money
+	Tax       Money
+	Total     Money
+}
+
+// CalculateTax computes the tax of lines worth amounts, each taxed at the
+// rate with the same index, in percent.
+func CalculateTax(amounts []Money, rates []float64, rules TaxRules) TaxBreakdown {
+	breakdown := TaxBreakdown{LineTaxes: make([]Money, len(amounts))}
+	var net Money
+	// Unrounded taxes are summed as amount × basis points; inclusive taxes
+	// have a different denominator per rate, so they are summed per rate.
+	exclusive := int64(0)
+	inclusive := map[int64]int64{}
+	for i, amount := range amounts {
+		bp := basisPoints(rates[i])
+		if rules.Inclusive {
+			breakdown.LineTaxes[i] = amount.TaxIncluded(rates[i], rules.Rounding)
+			inclusive[bp] += int64(amount)
+		} else {
+			breakdown.LineTaxes[i] = amount.Percent(rates[i], rules.Rounding)
+			exclusive += int64(amount) * bp
+		}
+		if rules.PerLine {
+			breakdown.Tax += breakdown.LineTaxes[i]
+		}
+		net += amount
+	}
+	if !rules.PerLine {
+		if rules.Inclusive {
+			for bp, amount := range inclusive {
+				breakdown.Tax += Money(amount).TaxIncluded(float64(bp)/100, rules.Rounding)
+			}
+		} else {
+			breakdown.Tax = divRound(exclusive, 10000, rules.Rounding)
+		}
+	}
+
+	breakdown.Total = net
+	if !rules.Inclusive {
+		breakdown.Total = net + breakdown.Tax
+	}
+	return breakdown
+}
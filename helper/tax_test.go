@@ -0,0 +1,96 @@
+package helper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCalculateTax(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []Money
+		rates   []float64
+		rules   TaxRules
+		lines   []Money
+		tax     Money
+		total   Money
+	}{
+		{
+			"exclusive per line rounds every line",
+			[]Money{105, 105}, []float64{10, 10},
+			TaxRules{Rounding: "half_up", PerLine: true},
+			[]Money{11, 11}, 22, 232,
+		},
+		{
+			"exclusive per transaction rounds once",
+			[]Money{105, 105}, []float64{10, 10},
+			TaxRules{Rounding: "half_up"},
+			[]Money{11, 11}, 21, 231,
+		},
+		{
+			"exclusive per line half even",
+			[]Money{105, 105}, []float64{10, 10},
+			TaxRules{Rounding: "half_even", PerLine: true},
+			[]Money{10, 10}, 20, 230,
+		},
+		{
+			"exclusive per transaction with mixed rates",
+			[]Money{105, 250}, []float64{10, 2.5},
+			TaxRules{Rounding: "half_up"},
+			[]Money{11, 6}, 17, 372,
+		},
+		{
+			"exclusive down",
+			[]Money{199}, []float64{10},
+			TaxRules{Rounding: "down", PerLine: true},
+			[]Money{19}, 19, 218,
+		},
+		{
+			"exclusive up",
+			[]Money{191}, []float64{10},
+			TaxRules{Rounding: "up"},
+			[]Money{20}, 20, 211,
+		},
+		{
+			"inclusive per line",
+			[]Money{115, 115, 100}, []float64{10, 10, 5},
+			TaxRules{Inclusive: true, Rounding: "half_up", PerLine: true},
+			[]Money{10, 10, 5}, 25, 330,
+		},
+		{
+			"inclusive per transaction rounds every rate once",
+			[]Money{115, 115, 100}, []float64{10, 10, 5},
+			TaxRules{Inclusive: true, Rounding: "half_up"},
+			[]Money{10, 10, 5}, 26, 330,
+		},
+		{
+			"inclusive rates are not pooled",
+			[]Money{110, 105}, []float64{10, 5},
+			TaxRules{Inclusive: true, Rounding: "half_up"},
+			[]Money{10, 5}, 15, 215,
+		},
+		{
+			"untaxed lines",
+			[]Money{1000, 500}, []float64{0, 0},
+			TaxRules{Rounding: "half_up"},
+			[]Money{0, 0}, 0, 1500,
+		},
+		{
+			"no lines",
+			[]Money{}, []float64{},
+			TaxRules{Inclusive: true, Rounding: "half_up"},
+			[]Money{}, 0, 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateTax(tt.amounts, tt.rates, tt.rules)
+			if !reflect.DeepEqual(got.LineTaxes, tt.lines) {
+				t.Errorf("line taxes = %v, want %v", got.LineTaxes, tt.lines)
+			}
+			if got.Tax != tt.tax || got.Total != tt.total {
+				t.Errorf("tax, total = %v, %v; want %v, %v", got.Tax, got.Total, tt.tax, tt.total)
+			}
+		})
+	}
+}
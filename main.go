@@ -8,6 +8,7 @@ import (
 	"os"
 	"time"
 
+	job "github.com/Project-Sprint-Golang/EniQilo-Store/app/jobs"
 	"github.com/Project-Sprint-Golang/EniQilo-Store/app/routes"
 	"github.com/Project-Sprint-Golang/EniQilo-Store/config"
 	"github.com/dgrijalva/jwt-go"
@@ -43,6 +44,12 @@ func main() {
 	// Setup routes
 	routes.SetupRouter(router)
 
+	// Background jobs
+	job.StartProductPurge()
+	job.StartReservationSweeper()
+	job.StartIdempotencyKeyPurge()
+	job.StartPointsExpiry()
+
 	// Define HTTP routes
 	// router.POST("/v1/staff/register", registerStaff)
 	// router.POST("/v1/customer/register", registerUser)